@@ -23,13 +23,13 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/pager"
 	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/yaml"
 )
@@ -45,12 +45,26 @@ type App struct {
 	managementClusters      map[string][]string // management cluster name -> API server IPs of managed clusters
 	mgmtClustersInitialized bool
 	mgmtClustersMutex       sync.RWMutex
+	clients                 *clientRegistry
+	watches                 *watchRegistry
+	searches                *taskRegistry
+	rollouts                *taskRegistry
+	portForwards            *portForwardRegistry
+	nodeShells              *nodeShellRegistry
+	copies                  *taskRegistry
 }
 
 // NewApp creates a new App.
 func NewApp() *App {
 	return &App{
 		managementClusters: make(map[string][]string),
+		clients:            newClientRegistry(),
+		watches:            newWatchRegistry(),
+		searches:           newTaskRegistry(),
+		rollouts:           newTaskRegistry(),
+		portForwards:       newPortForwardRegistry(),
+		nodeShells:         newNodeShellRegistry(),
+		copies:             newTaskRegistry(),
 	}
 }
 
@@ -66,6 +80,12 @@ func (a *App) startup(ctx context.Context) {
 	go a.StartWebSocketServer()
 }
 
+// shutdown is called when the app exits, after the frontend has been destroyed.
+func (a *App) shutdown(ctx context.Context) {
+	a.portForwards.stopAll()
+	a.stopNodeShells()
+}
+
 // Replace all your extraction functions with these:
 func extract[T any](obj map[string]interface{}, key string, defaultVal T) T {
 	if val, ok := obj[key].(T); ok {
@@ -95,50 +115,45 @@ type KubeClients struct {
 	Clientset     *kubernetes.Clientset
 	DynamicClient dynamic.Interface
 	RestConfig    *rest.Config
+	// Stream clients share the transport but have no request timeout, for follow and watch requests
+	StreamClientset     *kubernetes.Clientset
+	StreamDynamicClient dynamic.Interface
+	httpClient          *http.Client
+	discovery           *discoveryCache
 }
 
 func (a *App) getKubeClients(clusterName string) (*KubeClients, error) {
-	config, err := getClientConfig(clusterName)
-	if err != nil {
-		return nil, err
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, err
-	}
-
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return nil, err
-	}
-
-	return &KubeClients{
-		Clientset:     clientset,
-		DynamicClient: dynamicClient,
-		RestConfig:    config,
-	}, nil
+	return a.clients.get(clusterName)
 }
 
-// Consolidated resource finding
+// Consolidated resource finding.
+// resourceName may be a plural, singular or short name ("deploy"), a "resource.group"
+// or "resource.version.group" string, or a "group/version/resource" path.
 func (a *App) findResourceInfo(clusterName, resourceName string) (ResourceInfo, schema.GroupVersionResource, error) {
-	apiResources, err := a.GetApiResources(clusterName)
+	clients, err := a.getKubeClients(clusterName)
 	if err != nil {
-		return ResourceInfo{}, schema.GroupVersionResource{}, fmt.Errorf("failed to get API resources: %w", err)
+		return ResourceInfo{}, schema.GroupVersionResource{}, fmt.Errorf("failed to get clients: %w", err)
 	}
 
-	for _, resources := range apiResources {
-		for _, r := range resources {
-			if strings.EqualFold(r.Name, resourceName) {
-				gv, _ := schema.ParseGroupVersion(r.Version)
-				gvr := schema.GroupVersionResource{
-					Group:    gv.Group,
-					Version:  gv.Version,
-					Resource: r.Name,
-				}
-				return r, gvr, nil
-			}
+	for attempt := 0; attempt < 2; attempt++ {
+		groups, resourceLists, err := clients.discovery.groupsAndResources()
+		if err != nil {
+			return ResourceInfo{}, schema.GroupVersionResource{}, fmt.Errorf("failed to get API resources: %w", err)
+		}
+
+		r, found, err := resolveResource(groups, resourceLists, resourceName)
+		if err != nil {
+			return ResourceInfo{}, schema.GroupVersionResource{}, err
+		}
+		if found {
+			return r, r.GroupVersionResource(), nil
+		}
+
+		// The resource may have been installed after discovery was cached (e.g. a new CRD)
+		if !clients.discovery.invalidateAfterMiss() {
+			break
 		}
+		log.Printf("Resource %q not found in cached discovery for %s, refreshing", resourceName, clusterName)
 	}
 	return ResourceInfo{}, schema.GroupVersionResource{}, fmt.Errorf("resource %q not found", resourceName)
 }
@@ -151,11 +166,7 @@ func loadKubeConfig() (clientcmdapi.Config, error) {
 	).RawConfig()
 }
 
-func getClientConfig(clusterName string) (*rest.Config, error) {
-	kubeConfig, err := loadKubeConfig()
-	if err != nil {
-		return nil, err
-	}
+func restConfigFor(kubeConfig clientcmdapi.Config, clusterName string) (*rest.Config, error) {
 	if _, exists := kubeConfig.Contexts[clusterName]; !exists {
 		return nil, fmt.Errorf("context %q not found", clusterName)
 	}
@@ -282,8 +293,24 @@ func (a *App) GetDefaultNamespace(clusterName string) (string, error) {
 type ResourceInfo struct {
 	Name       string
 	Kind       string
+	Group      string
 	Version    string
 	Namespaced bool
+	ShortNames []string
+}
+
+// GroupVersionResource returns the GVR used to address the resource through the dynamic client.
+func (r ResourceInfo) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Name}
+}
+
+// qualifiedName returns the "resource.group" form that findResourceInfo resolves unambiguously.
+// The core group has no name, so its resources are qualified by version, as "events.v1".
+func (r ResourceInfo) qualifiedName() string {
+	if r.Group == "" {
+		return r.Name + "." + r.Version
+	}
+	return r.Name + "." + r.Group
 }
 
 // APIResourceMap is a map of group names to slices of ResourceInfo
@@ -295,56 +322,71 @@ func (a *App) GetApiResources(clusterName string) (APIResourceMap, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get clients: %w", err)
 	}
+	return apiResourcesFromDiscovery(clients.discovery)
+}
 
-	discoveryClient := discovery.NewDiscoveryClient(clients.Clientset.RESTClient())
-	_, apiGroupResources, err := discoveryClient.ServerGroupsAndResources()
+func apiResourcesFromDiscovery(d *discoveryCache) (APIResourceMap, error) {
+	_, apiGroupResources, err := d.groupsAndResources()
 	if err != nil {
-		if discoveryErr, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
-			log.Printf("Partial API group discovery failure: %v", discoveryErr.Groups)
-		} else {
-			return nil, fmt.Errorf("failed to retrieve API resources: %w", err)
-		}
+		return nil, err
 	}
 
 	apiResourcesMap := make(APIResourceMap)
 	for _, groupResource := range apiGroupResources {
-		if strings.HasPrefix(groupResource.GroupVersion, "metrics.k8s.io") {
+		gv, err := schema.ParseGroupVersion(groupResource.GroupVersion)
+		if err != nil {
 			continue
 		}
 
 		for _, resource := range groupResource.APIResources {
-			if strings.Contains(resource.Name, "/") || !slices.Contains(resource.Verbs, "list") {
+			if !isBrowsableResource(gv, resource) {
 				continue
 			}
-
-			apiResourcesMap[groupResource.GroupVersion] = append(apiResourcesMap[groupResource.GroupVersion], ResourceInfo{
-				Name:       resource.Name,
-				Kind:       resource.Kind,
-				Version:    groupResource.GroupVersion,
-				Namespaced: resource.Namespaced,
-			})
+			apiResourcesMap[groupResource.GroupVersion] = append(apiResourcesMap[groupResource.GroupVersion], newResourceInfo(gv, resource))
 		}
 	}
 
 	return apiResourcesMap, nil
 }
 
+// isBrowsableResource filters out subresources, non-listable resources and metrics.
+func isBrowsableResource(gv schema.GroupVersion, resource metav1.APIResource) bool {
+	return gv.Group != "metrics.k8s.io" &&
+		!strings.Contains(resource.Name, "/") &&
+		slices.Contains(resource.Verbs, "list")
+}
+
+func newResourceInfo(gv schema.GroupVersion, resource metav1.APIResource) ResourceInfo {
+	return ResourceInfo{
+		Name:       resource.Name,
+		Kind:       resource.Kind,
+		Group:      gv.Group,
+		Version:    gv.Version,
+		Namespaced: resource.Namespaced,
+		ShortNames: resource.ShortNames,
+	}
+}
+
 // ResourceResponse describes a single Kubernetes resource in a simpler form.
 type ResourceResponse struct {
-	Name     string                 `json:"name"`
-	Kind     string                 `json:"kind"`
-	Metadata map[string]interface{} `json:"metadata"`
-	Spec     map[string]interface{} `json:"spec"`
-	Age      string                 `json:"age"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Kind      string                 `json:"kind"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	Spec      map[string]interface{} `json:"spec"`
+	Age       string                 `json:"age"`
 }
 
 // PodResponse extends ResourceResponse with Pod-specific fields.
 type PodResponse struct {
 	ResourceResponse
-	Status      string   `json:"status"`
-	Restarts    int32    `json:"restarts"`
-	ReadyStatus string   `json:"readyStatus"`
-	Containers  []string `json:"containers"` // New field for container names (including init containers)
+	Status          string   `json:"status"`
+	Restarts        int32    `json:"restarts"`
+	RestartsDisplay string   `json:"restartsDisplay"` // Format like "3 (5m ago)"
+	ReadyStatus     string   `json:"readyStatus"`
+	ReadinessGates  string   `json:"readinessGates,omitempty"` // Format like "1/2"
+	NominatedNode   string   `json:"nominatedNode,omitempty"`
+	Containers      []string `json:"containers"` // New field for container names (including init containers)
 }
 
 // DeploymentResponse extends ResourceResponse with Deployment-specific fields.
@@ -376,7 +418,13 @@ func formatAge(timestamp string) string {
 }
 
 // Now the simplified main function:
-func (a *App) GetResourcesInNamespace(clusterName, resourceName, namespace string) ([]interface{}, error) {
+// Selectors are applied on the server. The namespace AllNamespaces lists namespaced
+// resources of every namespace the user can see.
+func (a *App) GetResourcesInNamespace(clusterName, resourceName, namespace string, selectors ResourceSelectors) ([]interface{}, error) {
+	if err := selectors.validate(); err != nil {
+		return nil, err
+	}
+
 	clients, err := a.getKubeClients(clusterName)
 	if err != nil {
 		return nil, err
@@ -387,79 +435,131 @@ func (a *App) GetResourcesInNamespace(clusterName, resourceName, namespace strin
 		return nil, err
 	}
 
+	if resourceInfo.Namespaced && namespace == AllNamespaces {
+		return a.listAllNamespaces(clusterName, clients, resourceInfo, gvr, selectors)
+	}
+	return listResources(clients, resourceInfo, gvr, namespace, selectors)
+}
+
+// listResources lists a whole collection with the response type of its kind.
+func listResources(clients *KubeClients, resourceInfo ResourceInfo, gvr schema.GroupVersionResource, namespace string, selectors ResourceSelectors) ([]interface{}, error) {
+	// List in chunks so that huge collections don't run into the request timeout
 	resourceClient := resourceInterface(clients.DynamicClient, gvr, resourceInfo.Namespaced, namespace)
-	list, err := resourceClient.List(context.Background(), metav1.ListOptions{})
+	listPager := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return resourceClient.List(ctx, opts)
+	})
+	listPager.PageSize = listPageSize
+
+	var listOptions metav1.ListOptions
+	selectors.applyTo(&listOptions)
+
+	var responses []interface{}
+	err := listPager.EachListItem(context.Background(), listOptions, func(obj runtime.Object) error {
+		if item, ok := obj.(*unstructured.Unstructured); ok {
+			responses = append(responses, toResourceResponse(schema.GroupKind{Group: gvr.Group, Kind: resourceInfo.Kind}, *item))
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list resources: %w", err)
 	}
+	return responses, nil
+}
 
-	var responses []interface{}
-	for _, item := range list.Items {
-		base := ResourceResponse{
-			Name:     item.GetName(),
-			Kind:     item.GetKind(),
-			Metadata: extractMap(item.Object, "metadata"),
-			Spec:     extractMap(item.Object, "spec"),
-			Age:      formatAge(item.GetCreationTimestamp().Format(timeFormat)),
-		}
+// toResourceResponse converts a listed item into the response for its group and kind.
+func toResourceResponse(groupKind schema.GroupKind, item unstructured.Unstructured) interface{} {
+	base := ResourceResponse{
+		Name:      item.GetName(),
+		Namespace: item.GetNamespace(),
+		Kind:      item.GetKind(),
+		Metadata:  extractMap(item.Object, "metadata"),
+		Spec:      extractMap(item.Object, "spec"),
+		Age:       formatAge(item.GetCreationTimestamp().Format(timeFormat)),
+	}
 
-		switch strings.ToLower(resourceInfo.Kind) {
-		case "pod":
-			p, err := toPod(item)
-			if err != nil {
-				log.Printf("toPod error: %v", err)
-				responses = append(responses, base)
-				break
-			}
-			status, restarts, readyStatus, containers := summarizePod(p)
-			pod := PodResponse{
-				ResourceResponse: base,
-				Status:           status,
-				Restarts:         restarts,
-				ReadyStatus:      readyStatus,
-				Containers:       containers,
-			}
-			responses = append(responses, pod)
-		case "deployment":
-			d, err := toDeployment(item)
-			if err != nil {
-				log.Printf("toDeployment error: %v", err)
-				responses = append(responses, base)
-				break
-			}
-			ready, upToDate, available := summarizeDeployment(d)
-			dep := DeploymentResponse{
-				ResourceResponse: base,
-				Ready:            ready,
-				UpToDate:         upToDate,
-				Available:        available,
-			}
-			responses = append(responses, dep)
-		default:
-			responses = append(responses, base)
+	switch groupKind {
+	case schema.GroupKind{Kind: "Pod"}:
+		p, err := toPod(item)
+		if err != nil {
+			log.Printf("toPod error: %v", err)
+			return base
+		}
+		summary := summarizePod(p, time.Now())
+		return PodResponse{
+			ResourceResponse: base,
+			Status:           summary.status,
+			Restarts:         summary.restarts,
+			RestartsDisplay:  summary.restartsText,
+			ReadyStatus:      summary.ready,
+			ReadinessGates:   summary.readinessGates,
+			NominatedNode:    summary.nominatedNode,
+			Containers:       summary.containers,
 		}
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"}:
+		d, err := toDeployment(item)
+		if err != nil {
+			log.Printf("toDeployment error: %v", err)
+			return base
+		}
+		ready, upToDate, available := summarizeDeployment(d)
+		return DeploymentResponse{
+			ResourceResponse: base,
+			Ready:            ready,
+			UpToDate:         upToDate,
+			Available:        available,
+		}
+	default:
+		response, ok, err := summarizeKind(groupKind, base, item)
+		if !ok {
+			return base
+		}
+		if err != nil {
+			log.Printf("summarize %s error: %v", groupKind, err)
+			return base
+		}
+		return response
 	}
-	return responses, nil
 }
 
 // GetResourceYAML retrieves the YAML representation of a specific resource
 func (a *App) GetResourceYAML(clusterName, resourceName, namespace, name string) (string, error) {
-	clients, err := a.getKubeClients(clusterName)
+	resource, err := a.GetResourceForEdit(clusterName, resourceName, namespace, name)
 	if err != nil {
 		return "", err
 	}
+	return resource.YAML, nil
+}
+
+// GetResourceForEdit returns the resource YAML along with the resourceVersion it was read at,
+// so that saving it through ApplyResourceWithOptions can detect concurrent changes.
+func (a *App) GetResourceForEdit(clusterName, resourceName, namespace, name string) (*EditableResource, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
 
 	resourceInfo, gvr, err := a.findResourceInfo(clusterName, resourceName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resourceClient := resourceInterface(clients.DynamicClient, gvr, resourceInfo.Namespaced, namespace)
 	obj, err := resourceClient.Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to get resource: %w", err)
+		return nil, fmt.Errorf("failed to get resource: %w", err)
 	}
 
+	yamlContent, err := editableYAML(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &EditableResource{YAML: yamlContent, ResourceVersion: obj.GetResourceVersion()}, nil
+}
+
+// editableYAML encodes the object as YAML without server-managed metadata.
+func editableYAML(obj *unstructured.Unstructured) (string, error) {
+	obj = obj.DeepCopy()
+
 	// Clean up metadata
 	if metadata := extractMap(obj.Object, "metadata"); len(metadata) > 0 {
 		for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "managedFields"} {
@@ -547,54 +647,6 @@ func (p *OllamaProxy) ForwardToOllama(requestBody string) (string, error) {
 	return string(body), nil
 }
 
-// ApplyResource applies a Kubernetes resource from YAML to the specified cluster.
-func (a *App) ApplyResource(clusterName string, yamlContent string) error {
-	obj := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal([]byte(yamlContent), obj); err != nil {
-		return fmt.Errorf("failed to decode YAML: %w", err)
-	}
-
-	clients, err := a.getKubeClients(clusterName)
-	if err != nil {
-		return fmt.Errorf("failed to get clients: %w", err)
-	}
-
-	gvk := obj.GroupVersionKind()
-	gvr := schema.GroupVersionResource{
-		Group:    gvk.Group,
-		Version:  gvk.Version,
-		Resource: strings.ToLower(gvk.Kind) + "s",
-	}
-
-	namespace := obj.GetNamespace()
-	if namespace == "" {
-		namespace = defaultNamespace
-	}
-
-	existingObj, err := clients.DynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), obj.GetName(), metav1.GetOptions{})
-	if err != nil {
-		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to check if resource exists: %w", err)
-		}
-		// Create new resource
-		_, err := clients.DynamicClient.Resource(gvr).Namespace(namespace).Create(context.Background(), obj, metav1.CreateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to create resource: %w", err)
-		}
-		log.Printf("Resource %q of type %q created successfully in namespace %q in cluster %q", obj.GetName(), gvk.Kind, namespace, clusterName)
-		return nil
-	}
-
-	// Update existing resource
-	obj.SetResourceVersion(existingObj.GetResourceVersion())
-	_, err = clients.DynamicClient.Resource(gvr).Namespace(namespace).Update(context.Background(), obj, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update resource: %w", err)
-	}
-	log.Printf("Resource %q of type %q updated successfully in namespace %q in cluster %q", obj.GetName(), gvk.Kind, namespace, clusterName)
-	return nil
-}
-
 // WebSocket configuration
 var upgrader = websocket.Upgrader{
 	EnableCompression: true,
@@ -606,6 +658,8 @@ var upgrader = websocket.Upgrader{
 // StartWebSocketServer initializes the WebSocket server for terminal sessions
 func (a *App) StartWebSocketServer() {
 	http.HandleFunc("/terminal", a.handleTerminalWebSocket)
+	http.HandleFunc("/logs", a.handleLogsWebSocket)
+	http.HandleFunc("/workload-logs", a.handleWorkloadLogsWebSocket)
 	http.HandleFunc("/envoy", a.handleEnvoyConfig)
 	port := "34116"
 	log.Printf("WebSocket server listening on port %s", port)
@@ -629,8 +683,13 @@ func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
 	}
 }
 
-// Handle WebSocket connections for terminal sessions
+// Handle WebSocket connections for terminal sessions. The default "exec" mode runs
+// the command, "attach" mode connects to the container's running process instead.
 func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
+	// A node shell pod lives only as long as its session, also if it fails to start
+	a.nodeShells.attach(r.URL.Query().Get("cluster"), r.URL.Query().Get("namespace"), r.URL.Query().Get("pod"))
+	defer a.endNodeShell(r.URL.Query().Get("cluster"), r.URL.Query().Get("namespace"), r.URL.Query().Get("pod"))
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
@@ -646,6 +705,10 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	namespace := query.Get("namespace")
 	podName := query.Get("pod")
 	containerName := query.Get("container")
+	mode := query.Get("mode")
+	if mode == "" {
+		mode = "exec"
+	}
 
 	command := []string{"/bin/sh"}
 	if commandParam := query.Get("command"); commandParam != "" {
@@ -657,12 +720,33 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	config, req, err := a.setupExecRequest(clusterName, namespace, podName, containerName, command, true)
+	var config *rest.Config
+	var req *rest.Request
+	stdin, tty := true, true
+	switch mode {
+	case "exec":
+		config, req, err = a.setupExecRequest(clusterName, namespace, podName, containerName, command, true, true)
+	case "attach":
+		stdin, tty, err = a.containerStreams(clusterName, namespace, podName, containerName)
+		if err == nil {
+			config, req, err = a.setupAttachRequest(clusterName, namespace, podName, containerName, stdin, tty)
+		}
+	default:
+		err = fmt.Errorf("unknown terminal mode %q", mode)
+	}
 	if err != nil {
-		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, closeReason(err.Error())))
 		return
 	}
 
+	// A process started without stdin or tty can still be watched
+	if !stdin {
+		conn.WriteMessage(websocket.TextMessage, []byte("Container has no stdin, input is ignored\r\n"))
+	}
+	if !tty {
+		conn.WriteMessage(websocket.TextMessage, []byte("Container has no TTY, output may be garbled and resizing is not supported\r\n"))
+	}
+
 	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
 	if err != nil {
 		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
@@ -672,7 +756,6 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Set up bidirectional streams
 	stdinReader, stdinWriter := io.Pipe()
 	stdoutReader, stdoutWriter := io.Pipe()
-	_, stderrWriter := io.Pipe()
 
 	sizeChan := make(chan remotecommand.TerminalSize, 1)
 	tsQueue := &terminalSizeQueue{
@@ -687,30 +770,37 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Handle streams
 	go func() {
 		defer wg.Done()
-		defer stderrWriter.Close()
 
-		// Set up StreamOptions with TerminalSizeQueue
+		// Set up StreamOptions, with TerminalSizeQueue for TTYs.
+		// Without a TTY stderr is a separate stream, merged into the output
 		streamOpts := remotecommand.StreamOptions{
-			Stdin:             stdinReader,
-			Stdout:            stdoutWriter,
-			Stderr:            stderrWriter,
-			Tty:               true,
-			TerminalSizeQueue: tsQueue, // Add terminal size queue
+			Stdout: stdoutWriter,
+			Tty:    tty,
+		}
+		if stdin {
+			streamOpts.Stdin = stdinReader
+		}
+		if tty {
+			streamOpts.TerminalSizeQueue = tsQueue
+		} else {
+			streamOpts.Stderr = stdoutWriter
 		}
 
-		log.Printf("Executing command in pod %s/%s, container %s", namespace, podName, containerName)
-		if err := executor.StreamWithContext(ctx, streamOpts); err != nil {
+		log.Printf("Starting %s session in pod %s/%s, container %s", mode, namespace, podName, containerName)
+		err := executor.StreamWithContext(ctx, streamOpts)
+		if err != nil {
 			log.Printf("Stream error: %v", err)
-			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr,
-				fmt.Sprintf("Stream error: %v", err)))
-			cancel() // Cancel context to stop other goroutines
 		}
+		// Ending the output ends the session, the stdout handler closes the WebSocket
+		stdoutWriter.CloseWithError(err)
 	}()
 
 	// Handle WebSocket messages (stdin)
 	go func() {
 		defer wg.Done()
 		defer stdinWriter.Close()
+		// The client is gone, so end the session
+		defer cancel()
 
 		for {
 			select {
@@ -733,6 +823,9 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 						Rows uint16 `json:"rows"`
 					}
 					if err := json.Unmarshal(data, &resizeMsg); err == nil && resizeMsg.Type == "resize" {
+						if !tty {
+							continue
+						}
 						select {
 						case sizeChan <- remotecommand.TerminalSize{
 							Width:  resizeMsg.Cols,
@@ -747,6 +840,9 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 				}
 
 				// Treat as regular input
+				if !stdin {
+					continue
+				}
 				log.Printf("Writing to stdinWriter...")
 				if _, err := stdinWriter.Write(data); err != nil {
 					if err != io.ErrClosedPipe {
@@ -762,6 +858,7 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	go func() {
 		defer wg.Done()
 		defer stdoutReader.Close()
+		defer cancel()
 
 		buf := make([]byte, 262144)
 		for {
@@ -771,9 +868,14 @@ func (a *App) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 			default:
 				n, err := stdoutReader.Read(buf)
 				if err != nil {
+					closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
 					if err != io.EOF && err != io.ErrClosedPipe {
 						log.Printf("Error reading from stdout: %v", err)
+						closeMessage = websocket.FormatCloseMessage(websocket.CloseInternalServerErr, closeReason(fmt.Sprintf("Stream error: %v", err)))
 					}
+					// The client answers the close message, which ends the stdin handler
+					conn.WriteMessage(websocket.CloseMessage, closeMessage)
+					conn.SetReadDeadline(time.Now().Add(5 * time.Second))
 					return
 				}
 				log.Printf("Read %d bytes from stdout", n)
@@ -810,7 +912,7 @@ func (a *App) handleEnvoyConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	command := strings.Split(commandParam, ",")
-	config, req, err := a.setupExecRequest(clusterName, namespace, podName, containerName, command, false)
+	config, req, err := a.setupExecRequest(clusterName, namespace, podName, containerName, command, false, false)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -919,7 +1021,74 @@ func getEventAge(eventData map[string]interface{}) string {
 	return "unknown"
 }
 
-func (a *App) setupExecRequest(clusterName, namespace, podName, containerName string, command []string, tty bool) (*rest.Config, *rest.Request, error) {
+// closeReason shortens a reason to fit into a WebSocket close message.
+func closeReason(reason string) string {
+	const maxCloseReason = 123
+	if len(reason) <= maxCloseReason {
+		return reason
+	}
+	return strings.ToValidUTF8(reason[:maxCloseReason-3], "") + "..."
+}
+
+// setupAttachRequest builds a request attaching to the running process of the container.
+func (a *App) setupAttachRequest(clusterName, namespace, podName, containerName string, stdin, tty bool) (*rest.Config, *rest.Request, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := clients.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("attach").
+		VersionedParams(&corev1.PodAttachOptions{
+			Container: containerName,
+			Stdin:     stdin,
+			Stdout:    true,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+
+	return clients.RestConfig, req, nil
+}
+
+// containerStreams tells whether the container was started with stdin and a TTY,
+// which is all an attach session can use.
+func (a *App) containerStreams(clusterName, namespace, podName, containerName string) (stdin, tty bool, err error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return false, false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pod, err := clients.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return false, false, err
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return false, false, fmt.Errorf("pod %q is not running (%s)", podName, pod.Status.Phase)
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			return c.Stdin, c.TTY, nil
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == containerName {
+			return c.Stdin, c.TTY, nil
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == containerName {
+			return c.Stdin, c.TTY, nil
+		}
+	}
+	return false, false, fmt.Errorf("pod %q has no container %q", podName, containerName)
+}
+
+func (a *App) setupExecRequest(clusterName, namespace, podName, containerName string, command []string, stdin, tty bool) (*rest.Config, *rest.Request, error) {
 	clients, err := a.getKubeClients(clusterName)
 	if err != nil {
 		return nil, nil, err
@@ -933,7 +1102,7 @@ func (a *App) setupExecRequest(clusterName, namespace, podName, containerName st
 		VersionedParams(&corev1.PodExecOptions{
 			Container: containerName,
 			Command:   command,
-			Stdin:     tty,
+			Stdin:     stdin,
 			Stdout:    true,
 			Stderr:    !tty,
 			TTY:       tty,
@@ -1052,6 +1221,18 @@ func (a *App) GetResourceDependencies(clusterName, apiResource, namespace, resou
 	return chain, nil
 }
 
+// selectorFromMap builds a label selector string from a Service-style selector map.
+func selectorFromMap(selector map[string]interface{}) string {
+	var selectorParts []string
+	for key, value := range selector {
+		if valueStr, ok := value.(string); ok {
+			selectorParts = append(selectorParts, fmt.Sprintf("%s=%s", key, valueStr))
+		}
+	}
+	slices.Sort(selectorParts)
+	return strings.Join(selectorParts, ",")
+}
+
 // findServiceDependencies finds resources related to a Service
 func (a *App) findServiceDependencies(clients *KubeClients, namespace, serviceName string) ([]ResourceRef, error) {
 	var dependencies []ResourceRef
@@ -1092,16 +1273,7 @@ func (a *App) findServiceDependencies(clients *KubeClients, namespace, serviceNa
 		selector := extractMap(spec, "selector")
 
 		if len(selector) > 0 {
-			// Build label selector string
-			var selectorParts []string
-			for key, value := range selector {
-				if valueStr, ok := value.(string); ok {
-					selectorParts = append(selectorParts, fmt.Sprintf("%s=%s", key, valueStr))
-				}
-			}
-
-			if len(selectorParts) > 0 {
-				labelSelector := strings.Join(selectorParts, ",")
+			if labelSelector := selectorFromMap(selector); labelSelector != "" {
 				podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
 				podsList, err := clients.DynamicClient.Resource(podsGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{
 					LabelSelector: labelSelector,
@@ -1132,8 +1304,7 @@ func (a *App) findChildResources(clients *KubeClients, namespace, ownerUID strin
 
 	// Get all API resources to search through
 	discoveryStart := time.Now()
-	discoveryClient := discovery.NewDiscoveryClient(clients.Clientset.RESTClient())
-	_, apiGroupResources, err := discoveryClient.ServerGroupsAndResources()
+	_, apiGroupResources, err := clients.discovery.groupsAndResources()
 	if err != nil {
 		return children, err
 	}
@@ -1949,37 +2120,6 @@ func toPod(u unstructured.Unstructured) (*corev1.Pod, error) {
 	return &p, err
 }
 
-func summarizePod(p *corev1.Pod) (status string, restarts int32, ready string, containers []string) {
-	if p.DeletionTimestamp != nil {
-		status = "Terminating"
-	} else {
-		status = string(p.Status.Phase)
-		for _, cs := range p.Status.ContainerStatuses {
-			restarts += cs.RestartCount
-			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
-				status = cs.State.Waiting.Reason
-			}
-			if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
-				status = cs.State.Terminated.Reason
-			}
-		}
-	}
-	readyCnt := 0
-	for _, cs := range p.Status.ContainerStatuses {
-		if cs.Ready {
-			readyCnt++
-		}
-	}
-	ready = fmt.Sprintf("%d/%d", readyCnt, len(p.Spec.Containers))
-	for _, c := range p.Spec.InitContainers {
-		containers = append(containers, c.Name)
-	}
-	for _, c := range p.Spec.Containers {
-		containers = append(containers, c.Name)
-	}
-	return
-}
-
 func toDeployment(u unstructured.Unstructured) (*appsv1.Deployment, error) {
 	var d appsv1.Deployment
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &d)
@@ -0,0 +1,620 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// fieldManager is the name kubeplorer writes under in managedFields.
+const fieldManager = "kubeplorer"
+
+// Actions reported in ApplyResult, named like kubectl apply output.
+const (
+	applyCreated    = "created"
+	applyConfigured = "configured"
+	applyUnchanged  = "unchanged"
+	applyFailed     = "failed"
+)
+
+// ApplyOptions controls how ApplyResourceWithOptions writes objects.
+type ApplyOptions struct {
+	ServerSide bool              `json:"serverSide"`         // Use server-side apply instead of get + create/update
+	Force      bool              `json:"force"`              // Take over fields owned by other managers (server-side only)
+	Original   *EditableResource `json:"original,omitempty"` // The object as opened in the editor, for conflict detection
+}
+
+// EditableResource is a resource as opened in the editor together with the
+// resourceVersion it was read at, which GetResourceYAML strips from the YAML.
+type EditableResource struct {
+	YAML            string `json:"yaml"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// EditConflictError is returned when an object changed on the server after it was opened
+// for editing. It carries a three-way view: the original, the edited and the live object.
+type EditConflictError struct {
+	Original            string `json:"original"`
+	Edited              string `json:"edited"`
+	Live                string `json:"live"`                          // Empty when the object was deleted
+	LiveResourceVersion string `json:"liveResourceVersion,omitempty"` // Pins an overwrite to the live object
+	TheirChanges        string `json:"theirChanges"`                  // Unified diff from original to live
+	YourChanges         string `json:"yourChanges"`                   // Unified diff from original to edited
+}
+
+func (e *EditConflictError) Error() string {
+	if e.Live == "" {
+		return "the object has been deleted since it was opened for editing"
+	}
+	return "the object has been modified since it was opened for editing"
+}
+
+// FieldConflict is a field owned by another manager that blocked a server-side apply.
+type FieldConflict struct {
+	Manager string `json:"manager"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ApplyResult describes what happened to an applied object.
+type ApplyResult struct {
+	Kind         string             `json:"kind"`
+	Name         string             `json:"name"`
+	Namespace    string             `json:"namespace,omitempty"`
+	Action       string             `json:"action"`
+	Conflicts    []FieldConflict    `json:"conflicts,omitempty"`
+	EditConflict *EditConflictError `json:"editConflict,omitempty"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// ApplyResource applies Kubernetes resources from YAML to the specified cluster.
+func (a *App) ApplyResource(clusterName string, yamlContent string) error {
+	results, err := a.ApplyResourceWithOptions(clusterName, yamlContent, ApplyOptions{})
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, result := range results {
+		if result.Action == applyFailed {
+			failures = append(failures, fmt.Sprintf("%s %q: %s", result.Kind, result.Name, result.Error))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to apply %d of %d resources: %s", len(failures), len(results), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// ApplyResourceWithOptions applies every object in the YAML, which may hold several documents
+// or a List, like kubectl apply -f. Each object gets its own result; field ownership conflicts
+// and per-object errors are reported in the results instead of failing the whole call.
+// With opts.Original set, the edited object is only written if it has not changed on the
+// server since it was opened; otherwise its result carries an EditConflictError.
+func (a *App) ApplyResourceWithOptions(clusterName string, yamlContent string, opts ApplyOptions) ([]ApplyResult, error) {
+	objs, err := decodeManifests(yamlContent)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := decodeOriginal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients: %w", err)
+	}
+
+	ctx := context.Background()
+	results := make([]ApplyResult, 0, len(objs))
+	crdsApplied := false
+	for _, obj := range objs {
+		isCRD := obj.GroupVersionKind().GroupKind() == crdGroupKind
+		// Kinds defined by CRDs earlier in the bundle are unknown to cached discovery
+		if crdsApplied && !isCRD {
+			clients.discovery.invalidate()
+			crdsApplied = false
+		}
+
+		result := applyObject(ctx, clients, obj, opts, original)
+		if result.Error != "" {
+			log.Printf("Failed to apply %s %q in namespace %q in cluster %q: %s", result.Kind, result.Name, result.Namespace, clusterName, result.Error)
+		} else {
+			log.Printf("Resource %q of type %q %s in namespace %q in cluster %q", result.Name, result.Kind, result.Action, result.Namespace, clusterName)
+		}
+		if isCRD && result.Action != applyFailed {
+			crdsApplied = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// decodeOriginal decodes the object opened in the editor, nil when there is none to check against.
+func decodeOriginal(opts ApplyOptions) (*unstructured.Unstructured, error) {
+	if opts.Original == nil || opts.Original.ResourceVersion == "" {
+		return nil, nil
+	}
+	originals, err := decodeManifests(opts.Original.YAML)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode original resource: %w", err)
+	}
+	return originals[0], nil
+}
+
+// applyObject applies a single object and folds any error into the result.
+func applyObject(ctx context.Context, clients *KubeClients, obj *unstructured.Unstructured, opts ApplyOptions, original *unstructured.Unstructured) ApplyResult {
+	resourceClient, _, err := resourceClientForObject(clients, obj)
+	if err != nil {
+		return failedApplyResult(obj, err)
+	}
+
+	// Pin the edited object to the version it was opened at, the server rejects stale writes
+	edited := original != nil && sameObject(original, obj)
+	if edited {
+		obj.SetResourceVersion(opts.Original.ResourceVersion)
+	}
+
+	var result *ApplyResult
+	if opts.ServerSide {
+		result, err = serverSideApply(ctx, resourceClient, obj, opts.Force)
+	} else {
+		result, err = createOrUpdate(ctx, resourceClient, obj)
+	}
+	if err != nil {
+		if edited && errors.IsConflict(err) {
+			return editConflictResult(ctx, resourceClient, original, obj)
+		}
+		return failedApplyResult(obj, err)
+	}
+	if len(result.Conflicts) > 0 {
+		result.Error = fmt.Sprintf("%d field(s) are managed by other field managers", len(result.Conflicts))
+	}
+	return *result
+}
+
+// sameObject reports whether both manifests describe the same object.
+func sameObject(a, b *unstructured.Unstructured) bool {
+	return a.GroupVersionKind().GroupKind() == b.GroupVersionKind().GroupKind() &&
+		a.GetName() == b.GetName() &&
+		(a.GetNamespace() == "" || a.GetNamespace() == b.GetNamespace())
+}
+
+// editConflictResult builds the three-way view for an object that changed after it was opened.
+func editConflictResult(ctx context.Context, resourceClient dynamic.ResourceInterface, original, edited *unstructured.Unstructured) ApplyResult {
+	conflict := &EditConflictError{}
+	var err error
+
+	if conflict.Original, err = editableYAML(original); err != nil {
+		return failedApplyResult(edited, err)
+	}
+	if conflict.Edited, err = editableYAML(edited); err != nil {
+		return failedApplyResult(edited, err)
+	}
+
+	liveObj, err := resourceClient.Get(ctx, edited.GetName(), metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return failedApplyResult(edited, fmt.Errorf("failed to get live resource: %w", err))
+	}
+	if err == nil {
+		if conflict.Live, err = editableYAML(liveObj); err != nil {
+			return failedApplyResult(edited, err)
+		}
+		conflict.LiveResourceVersion = liveObj.GetResourceVersion()
+	}
+
+	if conflict.TheirChanges, err = unifiedDiff(conflict.Original, conflict.Live, "original", "live"); err != nil {
+		return failedApplyResult(edited, err)
+	}
+	if conflict.YourChanges, err = unifiedDiff(conflict.Original, conflict.Edited, "original", "edited"); err != nil {
+		return failedApplyResult(edited, err)
+	}
+
+	result := failedApplyResult(edited, conflict)
+	result.EditConflict = conflict
+	return result
+}
+
+func failedApplyResult(obj *unstructured.Unstructured, err error) ApplyResult {
+	result := newApplyResult(obj)
+	result.Action = applyFailed
+	result.Error = err.Error()
+	return *result
+}
+
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+// applyOrder puts objects that others depend on first, lower values are applied earlier.
+var applyOrder = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 1,
+	"ResourceQuota":            2,
+	"LimitRange":               2,
+	"PriorityClass":            2,
+	"StorageClass":             2,
+	"ServiceAccount":           3,
+	"Secret":                   3,
+	"ConfigMap":                3,
+	"PersistentVolume":         3,
+	"PersistentVolumeClaim":    4,
+	"ClusterRole":              4,
+	"Role":                     4,
+	"ClusterRoleBinding":       5,
+	"RoleBinding":              5,
+}
+
+const defaultApplyOrder = 6
+
+// decodeManifests splits multi-document YAML into objects, expands List kinds
+// and orders the result so that namespaces and CRDs are applied first.
+func decodeManifests(yamlContent string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(yamlContent)))
+	for i := 1; ; i++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read YAML document %d: %w", i, err)
+		}
+
+		var content map[string]interface{}
+		if err := yaml.Unmarshal(doc, &content); err != nil {
+			return nil, fmt.Errorf("failed to decode YAML document %d: %w", i, err)
+		}
+		if len(content) == 0 {
+			continue // Empty document or comments only
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if !strings.HasSuffix(obj.GetKind(), "List") || !obj.IsList() {
+			objs = append(objs, obj)
+			continue
+		}
+		err = obj.EachListItem(func(item runtime.Object) error {
+			if u, ok := item.(*unstructured.Unstructured); ok {
+				objs = append(objs, u)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s in YAML document %d: %w", obj.GetKind(), i, err)
+		}
+	}
+
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("no Kubernetes objects found in YAML")
+	}
+
+	slices.SortStableFunc(objs, func(a, b *unstructured.Unstructured) int {
+		return manifestOrder(a) - manifestOrder(b)
+	})
+	return objs, nil
+}
+
+func manifestOrder(obj *unstructured.Unstructured) int {
+	if order, ok := applyOrder[obj.GetKind()]; ok {
+		return order
+	}
+	return defaultApplyOrder
+}
+
+// createOrUpdate creates the object or replaces the live one with it.
+func createOrUpdate(ctx context.Context, resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured) (*ApplyResult, error) {
+	result := newApplyResult(obj)
+
+	existingObj, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to check if resource exists: %w", err)
+		}
+		if obj.GetResourceVersion() != "" {
+			return nil, errors.NewConflict(schema.GroupResource{}, obj.GetName(), fmt.Errorf("the object has been deleted"))
+		}
+		// Create new resource
+		if _, err := resourceClient.Create(ctx, obj, metav1.CreateOptions{FieldManager: fieldManager}); err != nil {
+			return nil, fmt.Errorf("failed to create resource: %w", err)
+		}
+		result.Action = applyCreated
+		return result, nil
+	}
+
+	// Update existing resource. A resourceVersion in the manifest is kept as a precondition
+	if obj.GetResourceVersion() == "" {
+		obj.SetResourceVersion(existingObj.GetResourceVersion())
+	}
+	updated, err := resourceClient.Update(ctx, obj, metav1.UpdateOptions{FieldManager: fieldManager})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update resource: %w", err)
+	}
+	result.Action = actionForUpdate(existingObj, updated)
+	return result, nil
+}
+
+// serverSideApply sends the object as an apply patch owned by fieldManager.
+func serverSideApply(ctx context.Context, resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured, force bool) (*ApplyResult, error) {
+	result := newApplyResult(obj)
+
+	existingObj, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to check if resource exists: %w", err)
+		}
+		if obj.GetResourceVersion() != "" {
+			return nil, errors.NewConflict(schema.GroupResource{}, obj.GetName(), fmt.Errorf("the object has been deleted"))
+		}
+		existingObj = nil
+	}
+
+	applied, err := applyPatch(ctx, resourceClient, obj, force, false)
+	if err != nil {
+		if conflicts := fieldConflicts(err); len(conflicts) > 0 {
+			result.Action = applyFailed
+			result.Conflicts = conflicts
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to apply resource: %w", err)
+	}
+
+	if existingObj == nil {
+		result.Action = applyCreated
+	} else {
+		result.Action = actionForUpdate(existingObj, applied)
+	}
+	return result, nil
+}
+
+// applyPatch sends the object as a server-side apply patch owned by fieldManager.
+func applyPatch(ctx context.Context, resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured, force, dryRun bool) (*unstructured.Unstructured, error) {
+	// The API server rejects apply patches that carry managedFields
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	opts := metav1.PatchOptions{FieldManager: fieldManager, Force: &force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	return resourceClient.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, opts)
+}
+
+func newApplyResult(obj *unstructured.Unstructured) *ApplyResult {
+	return &ApplyResult{
+		Kind:      obj.GetKind(),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}
+}
+
+// actionForUpdate reports "unchanged" when the server did not bump the resourceVersion.
+func actionForUpdate(before, after *unstructured.Unstructured) string {
+	if before.GetResourceVersion() == after.GetResourceVersion() {
+		return applyUnchanged
+	}
+	return applyConfigured
+}
+
+var conflictManagerPattern = regexp.MustCompile(`conflict with "([^"]+)"`)
+
+// fieldConflicts extracts field manager conflicts from a server-side apply error.
+func fieldConflicts(err error) []FieldConflict {
+	statusErr, ok := err.(*errors.StatusError)
+	if !ok || !errors.IsConflict(err) || statusErr.ErrStatus.Details == nil {
+		return nil
+	}
+
+	var conflicts []FieldConflict
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflict := FieldConflict{Field: cause.Field, Message: cause.Message}
+		if m := conflictManagerPattern.FindStringSubmatch(cause.Message); m != nil {
+			conflict.Manager = m[1]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// ApplyPreview is the outcome of a dry-run apply together with a unified
+// diff between the live object and what the server would store.
+type ApplyPreview struct {
+	ApplyResult
+	Diff string `json:"diff"`
+}
+
+// PreviewApply dry-runs every object in the YAML the way ApplyResourceWithOptions would write
+// it with the same options, and diffs the results against the live objects. The diffs include
+// defaulted fields and admission webhook mutations. With opts.ServerSide, fields owned by other
+// managers are reported as conflicts and the diff shows what a forced apply would do.
+// With opts.Original set, an object that changed since it was opened gets an EditConflictError.
+func (a *App) PreviewApply(clusterName string, yamlContent string, opts ApplyOptions) ([]ApplyPreview, error) {
+	objs, err := decodeManifests(yamlContent)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := decodeOriginal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients: %w", err)
+	}
+
+	previews := make([]ApplyPreview, 0, len(objs))
+	for _, obj := range objs {
+		preview, err := previewObject(context.Background(), clients, obj, opts, original)
+		if err != nil {
+			preview = &ApplyPreview{ApplyResult: failedApplyResult(obj, err)}
+		}
+		previews = append(previews, *preview)
+	}
+	return previews, nil
+}
+
+func previewObject(ctx context.Context, clients *KubeClients, obj *unstructured.Unstructured, opts ApplyOptions, original *unstructured.Unstructured) (*ApplyPreview, error) {
+	resourceClient, _, err := resourceClientForObject(clients, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pinned like in applyObject, so that a stale edit shows up before anything is written
+	edited := original != nil && sameObject(original, obj)
+	if edited {
+		obj.SetResourceVersion(opts.Original.ResourceVersion)
+	}
+
+	preview := &ApplyPreview{ApplyResult: *newApplyResult(obj)}
+
+	liveObj, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get live resource: %w", err)
+		}
+		liveObj = nil
+	}
+
+	var dryRunObj *unstructured.Unstructured
+	switch {
+	case liveObj == nil && obj.GetResourceVersion() != "":
+		err = errors.NewConflict(schema.GroupResource{}, obj.GetName(), fmt.Errorf("the object has been deleted"))
+	case opts.ServerSide:
+		dryRunObj, preview.Conflicts, err = dryRunServerSideApply(ctx, resourceClient, obj)
+	default:
+		dryRunObj, err = dryRunCreateOrUpdate(ctx, resourceClient, obj, liveObj)
+	}
+	if err != nil {
+		if edited && errors.IsConflict(err) {
+			return &ApplyPreview{ApplyResult: editConflictResult(ctx, resourceClient, original, obj)}, nil
+		}
+		return nil, fmt.Errorf("dry-run failed: %w", err)
+	}
+
+	liveYAML := ""
+	if liveObj != nil {
+		if liveYAML, err = editableYAML(liveObj); err != nil {
+			return nil, err
+		}
+	}
+	dryRunYAML, err := editableYAML(dryRunObj)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.Diff, err = unifiedDiff(liveYAML, dryRunYAML, "live/"+objectPath(obj), "edited/"+objectPath(obj))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case liveObj == nil:
+		preview.Action = applyCreated
+	case preview.Diff == "":
+		preview.Action = applyUnchanged
+	default:
+		preview.Action = applyConfigured
+	}
+	return preview, nil
+}
+
+// dryRunServerSideApply dry-runs the apply patch. Fields owned by other managers are returned
+// as conflicts together with the object a forced apply would store.
+func dryRunServerSideApply(ctx context.Context, resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured) (*unstructured.Unstructured, []FieldConflict, error) {
+	dryRunObj, err := applyPatch(ctx, resourceClient, obj, false, true)
+	if err == nil {
+		return dryRunObj, nil, nil
+	}
+	conflicts := fieldConflicts(err)
+	if len(conflicts) == 0 {
+		return nil, nil, err
+	}
+	dryRunObj, err = applyPatch(ctx, resourceClient, obj, true, true)
+	return dryRunObj, conflicts, err
+}
+
+// dryRunCreateOrUpdate dry-runs the create or update that createOrUpdate would send.
+func dryRunCreateOrUpdate(ctx context.Context, resourceClient dynamic.ResourceInterface, obj, liveObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	dryRun := []string{metav1.DryRunAll}
+	if liveObj == nil {
+		return resourceClient.Create(ctx, obj, metav1.CreateOptions{FieldManager: fieldManager, DryRun: dryRun})
+	}
+	obj = obj.DeepCopy()
+	if obj.GetResourceVersion() == "" {
+		obj.SetResourceVersion(liveObj.GetResourceVersion())
+	}
+	return resourceClient.Update(ctx, obj, metav1.UpdateOptions{FieldManager: fieldManager, DryRun: dryRun})
+}
+
+func unifiedDiff(a, b, fromFile, toFile string) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to diff resource: %w", err)
+	}
+	return diff, nil
+}
+
+// objectPath names an object in diffs, e.g. "default/Deployment/web".
+func objectPath(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + "/" + obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetKind() + "/" + obj.GetName()
+}
+
+// resourceClientForObject maps the object's kind to its resource through discovery and returns
+// a client for it. Namespaced objects without a namespace go to "default", cluster-scoped
+// objects have their namespace cleared.
+func resourceClientForObject(clients *KubeClients, obj *unstructured.Unstructured) (dynamic.ResourceInterface, *meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return nil, nil, fmt.Errorf("object %q has no apiVersion or kind", obj.GetName())
+	}
+
+	mapping, err := clients.discovery.restMapping(gvk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find resource for %s: %w", gvk.String(), err)
+	}
+
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	if !namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNamespace)
+	}
+
+	return resourceInterface(clients.DynamicClient, mapping.Resource, namespaced, obj.GetNamespace()), mapping, nil
+}
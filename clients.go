@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// clientRegistry caches KubeClients per kubeconfig context.
+// Entries are keyed by context name and a fingerprint of the context's cluster and
+// user, so editing the kubeconfig drops only the clients whose settings changed.
+type clientRegistry struct {
+	mu           sync.Mutex
+	loadingRules *clientcmd.ClientConfigLoadingRules
+	kubeConfig   *clientcmdapi.Config
+	stamps       map[string]fileStamp
+	entries      map[string]*clientEntry
+}
+
+// fileStamp is used to detect kubeconfig changes without parsing the files.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type clientEntry struct {
+	fingerprint string
+	clients     *KubeClients
+}
+
+func newClientRegistry() *clientRegistry {
+	return &clientRegistry{
+		loadingRules: clientcmd.NewDefaultClientConfigLoadingRules(),
+		stamps:       make(map[string]fileStamp),
+		entries:      make(map[string]*clientEntry),
+	}
+}
+
+// get returns cached clients for the context, building them on first use
+// or after the context's kubeconfig entries have changed.
+func (r *clientRegistry) get(clusterName string) (*KubeClients, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kubeConfig, err := r.currentConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := contextFingerprint(kubeConfig, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry, ok := r.entries[clusterName]; ok {
+		if entry.fingerprint == fingerprint {
+			return entry.clients, nil
+		}
+		log.Printf("Kubeconfig for context %q changed, rebuilding clients", clusterName)
+		entry.clients.close()
+		delete(r.entries, clusterName)
+	}
+
+	clients, err := newKubeClients(kubeConfig, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	r.entries[clusterName] = &clientEntry{fingerprint: fingerprint, clients: clients}
+	return clients, nil
+}
+
+// evict drops the cached clients for the context and closes their idle connections.
+func (r *clientRegistry) evict(clusterName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[clusterName]; ok {
+		entry.clients.close()
+		delete(r.entries, clusterName)
+	}
+}
+
+// currentConfig returns the merged kubeconfig, reloading it only when one of
+// the files in the loading precedence was modified, created or removed.
+func (r *clientRegistry) currentConfig() (clientcmdapi.Config, error) {
+	stamps := make(map[string]fileStamp)
+	paths := r.loadingRules.GetLoadingPrecedence()
+	if r.loadingRules.ExplicitPath != "" {
+		paths = append(paths, r.loadingRules.ExplicitPath)
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	if r.kubeConfig != nil && stampsEqual(r.stamps, stamps) {
+		return *r.kubeConfig, nil
+	}
+
+	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		r.loadingRules,
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return clientcmdapi.Config{}, err
+	}
+	r.kubeConfig = &kubeConfig
+	r.stamps = stamps
+	return kubeConfig, nil
+}
+
+func stampsEqual(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		other, ok := b[path]
+		if !ok || !stamp.modTime.Equal(other.modTime) || stamp.size != other.size {
+			return false
+		}
+	}
+	return true
+}
+
+// contextFingerprint hashes everything that affects how clients for the context are built.
+func contextFingerprint(kubeConfig clientcmdapi.Config, clusterName string) (string, error) {
+	kubeContext, exists := kubeConfig.Contexts[clusterName]
+	if !exists {
+		return "", fmt.Errorf("context %q not found", clusterName)
+	}
+
+	var parts struct {
+		Context  clientcmdapi.Context
+		Cluster  *clientcmdapi.Cluster
+		AuthInfo *clientcmdapi.AuthInfo
+	}
+	parts.Context = *kubeContext
+	parts.Context.Extensions = nil
+	if cluster, ok := kubeConfig.Clusters[kubeContext.Cluster]; ok {
+		c := *cluster
+		c.Extensions = nil
+		parts.Cluster = &c
+	}
+	if authInfo, ok := kubeConfig.AuthInfos[kubeContext.AuthInfo]; ok {
+		ai := *authInfo
+		ai.Extensions = nil
+		parts.AuthInfo = &ai
+	}
+
+	data, err := json.Marshal(parts)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint context %q: %w", clusterName, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newKubeClients builds a clientset and a dynamic client sharing one HTTP client,
+// so both reuse the same transport and credential plugin.
+func newKubeClients(kubeConfig clientcmdapi.Config, clusterName string) (*KubeClients, error) {
+	config, err := restConfigFor(kubeConfig, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Long-running requests must not be cut off by the request timeout
+	streamConfig := rest.CopyConfig(config)
+	streamConfig.Timeout = 0
+	streamHTTPClient := &http.Client{Transport: httpClient.Transport}
+
+	streamClientset, err := kubernetes.NewForConfigAndClient(streamConfig, streamHTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	streamDynamicClient, err := dynamic.NewForConfigAndClient(streamConfig, streamHTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryCache, err := newDiscoveryCache(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KubeClients{
+		Clientset:           clientset,
+		DynamicClient:       dynamicClient,
+		RestConfig:          config,
+		StreamClientset:     streamClientset,
+		StreamDynamicClient: streamDynamicClient,
+		httpClient:          httpClient,
+		discovery:           discoveryCache,
+	}, nil
+}
+
+func (c *KubeClients) close() {
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+}
+
+// DisconnectCluster evicts cached clients for the cluster so the next call reconnects from scratch.
+func (a *App) DisconnectCluster(clusterName string) {
+	a.watches.stopCluster(clusterName)
+	a.clients.evict(clusterName)
+	log.Printf("Disconnected from cluster %s", clusterName)
+}
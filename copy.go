@@ -0,0 +1,507 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// copyProgressInterval throttles the progress events of a copy.
+const copyProgressInterval = 200 * time.Millisecond
+
+// maxCopyStderr caps the output of tar kept for error messages.
+const maxCopyStderr = 4096
+
+// Copy event types.
+const (
+	copyProgress = "progress"
+	copyDone     = "done"
+	copyError    = "error"
+)
+
+// CopyTransfer identifies a running copy and the event its progress is emitted as.
+// Path is the local file or directory chosen in the dialog.
+type CopyTransfer struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	Path  string `json:"path"`
+}
+
+// CopyEvent is emitted while a copy runs. Total is 0 if the size isn't known in
+// advance, which is the case for copies from a pod. Skipped lists archive entries
+// that weren't extracted, like links pointing outside of the target directory.
+type CopyEvent struct {
+	Type    string   `json:"type"`
+	Bytes   int64    `json:"bytes"`
+	Total   int64    `json:"total,omitempty"`
+	File    string   `json:"file,omitempty"`
+	Skipped []string `json:"skipped,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// copyReporter counts the copied bytes and emits throttled progress events.
+type copyReporter struct {
+	emit  func(CopyEvent)
+	bytes int64
+	total int64
+	file  string
+	last  time.Time
+}
+
+func (r *copyReporter) add(n int64) {
+	r.bytes += n
+	if time.Since(r.last) >= copyProgressInterval {
+		r.last = time.Now()
+		r.emit(CopyEvent{Type: copyProgress, Bytes: r.bytes, Total: r.total, File: r.file})
+	}
+}
+
+// Write lets the reporter count what is copied through an io.MultiWriter.
+func (r *copyReporter) Write(p []byte) (int, error) {
+	r.add(int64(len(p)))
+	return len(p), nil
+}
+
+// CopyFromPod copies a file or directory out of a container, like kubectl cp. A save
+// dialog asks where to store a file, a directory dialog where to put a directory.
+// The copy runs in the background and emits CopyEvents until a "done" or "error" event.
+// It returns nil if the dialog was cancelled. The container needs tar.
+func (a *App) CopyFromPod(clusterName, namespace, podName, containerName, remotePath string) (*CopyTransfer, error) {
+	remotePath = path.Clean(remotePath)
+	if remotePath == "" || remotePath == "." || remotePath == "/" {
+		return nil, fmt.Errorf("invalid path %q", remotePath)
+	}
+	remoteDir, name := path.Split(remotePath)
+	if remoteDir == "" {
+		remoteDir = "."
+	}
+
+	isDir, err := a.isRemoteDir(clusterName, namespace, podName, containerName, remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var localPath string
+	if isDir {
+		localPath, err = wailsruntime.OpenDirectoryDialog(a.ctx, wailsruntime.OpenDialogOptions{
+			Title:                fmt.Sprintf("Copy %s to", remotePath),
+			CanCreateDirectories: true,
+		})
+	} else {
+		localPath, err = wailsruntime.SaveFileDialog(a.ctx, wailsruntime.SaveDialogOptions{
+			Title:           fmt.Sprintf("Save %s", remotePath),
+			DefaultFilename: name,
+		})
+	}
+	if err != nil || localPath == "" {
+		return nil, err
+	}
+
+	command := []string{"tar", "cf", "-", "-C", remoteDir, name}
+	transfer := a.startCopy(localPath, func(ctx context.Context, reporter *copyReporter) ([]string, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		reader, writer := io.Pipe()
+		var stderr limitedBuffer
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			writer.CloseWithError(a.streamExec(ctx, clusterName, namespace, podName, containerName, command, nil, writer, &stderr))
+		}()
+
+		var skipped []string
+		var err error
+		if isDir {
+			skipped, err = extractTar(reader, localPath, reporter)
+		} else {
+			err = extractFile(reader, name, localPath, reporter)
+		}
+		// Stop tar if extracting failed, and wait for its output
+		if err != nil {
+			cancel()
+		}
+		reader.Close()
+		<-done
+		cancel()
+		if err != nil && stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return skipped, err
+	})
+	log.Printf("Copying %s from pod %s/%s, container %s to %s", remotePath, namespace, podName, containerName, localPath)
+	return transfer, nil
+}
+
+// CopyToPod copies a local file, or a directory if directory is set, chosen in an open
+// dialog into remoteDir of a container, like kubectl cp. Symlinks are copied as links.
+// The copy runs in the background and emits CopyEvents until a "done" or "error" event.
+// It returns nil if the dialog was cancelled. The container needs tar.
+func (a *App) CopyToPod(clusterName, namespace, podName, containerName, remoteDir string, directory bool) (*CopyTransfer, error) {
+	if remoteDir == "" {
+		return nil, fmt.Errorf("no target directory")
+	}
+
+	var localPath string
+	var err error
+	if directory {
+		localPath, err = wailsruntime.OpenDirectoryDialog(a.ctx, wailsruntime.OpenDialogOptions{
+			Title: fmt.Sprintf("Copy a directory to %s", remoteDir),
+		})
+	} else {
+		localPath, err = wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+			Title: fmt.Sprintf("Copy a file to %s", remoteDir),
+		})
+	}
+	if err != nil || localPath == "" {
+		return nil, err
+	}
+
+	total, err := localSize(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	command := []string{"tar", "xmf", "-", "-C", remoteDir}
+	transfer := a.startCopy(localPath, func(ctx context.Context, reporter *copyReporter) ([]string, error) {
+		reporter.total = total
+		reader, writer := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			writer.CloseWithError(writeTar(writer, localPath, reporter))
+		}()
+
+		var stderr limitedBuffer
+		err := a.streamExec(ctx, clusterName, namespace, podName, containerName, command, reader, io.Discard, &stderr)
+		// Stop archiving if tar failed, the reporter is used afterwards
+		reader.CloseWithError(err)
+		<-done
+		if err != nil && stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	})
+	log.Printf("Copying %s to pod %s/%s, container %s into %s", localPath, namespace, podName, containerName, remoteDir)
+	return transfer, nil
+}
+
+// CancelCopy stops a running copy. Files copied so far are kept.
+func (a *App) CancelCopy(copyID string) {
+	a.copies.stop(copyID)
+}
+
+// startCopy runs a copy in the background and emits its progress and result.
+func (a *App) startCopy(localPath string, run func(ctx context.Context, reporter *copyReporter) ([]string, error)) *CopyTransfer {
+	ctx, cancel := context.WithCancel(context.Background())
+	id := a.copies.start(cancel)
+	transfer := &CopyTransfer{ID: id, Event: "copy:" + id, Path: localPath}
+
+	go func() {
+		defer a.copies.stop(id)
+		emit := func(event CopyEvent) {
+			if a.ctx != nil && ctx.Err() == nil {
+				wailsruntime.EventsEmit(a.ctx, transfer.Event, event)
+			}
+		}
+		reporter := &copyReporter{emit: emit}
+		skipped, err := run(ctx, reporter)
+		if err != nil {
+			log.Printf("Copy %s failed: %v", localPath, err)
+			emit(CopyEvent{Type: copyError, Bytes: reporter.bytes, Total: reporter.total, Skipped: skipped, Error: err.Error()})
+			return
+		}
+		emit(CopyEvent{Type: copyDone, Bytes: reporter.bytes, Total: reporter.total, Skipped: skipped})
+	}()
+	return transfer
+}
+
+// streamExec runs a command in the container without a TTY until it exits.
+func (a *App) streamExec(ctx context.Context, clusterName, namespace, podName, containerName string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	config, req, err := a.setupExecRequest(clusterName, namespace, podName, containerName, command, stdin != nil, false)
+	if err != nil {
+		return err
+	}
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// isRemoteDir tells whether the path in the container is a directory.
+func (a *App) isRemoteDir(clusterName, namespace, podName, containerName, remotePath string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var stderr limitedBuffer
+	err := a.streamExec(ctx, clusterName, namespace, podName, containerName, []string{"test", "-d", remotePath}, nil, io.Discard, &stderr)
+	var exitErr utilexec.ExitError
+	if stderrors.As(err, &exitErr) && exitErr.ExitStatus() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", remotePath, err)
+	}
+	return true, nil
+}
+
+// extractFile writes the single file of the archive to localPath.
+func extractFile(reader io.Reader, name, localPath string, reporter *copyReporter) error {
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in the archive", name)
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || path.Clean(header.Name) != name {
+			continue
+		}
+
+		reporter.file = name
+		if err := writeFile(localPath, archive, header.FileInfo().Mode().Perm(), reporter); err != nil {
+			return err
+		}
+		// Read the rest, so that tar exits normally
+		_, err = io.Copy(io.Discard, archive)
+		return err
+	}
+}
+
+// extractTar extracts the archive into root. Entries that would be written outside of
+// root, links pointing outside of it and special files are skipped and returned.
+func extractTar(reader io.Reader, root string, reporter *copyReporter) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	var skipped []string
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return skipped, nil
+		}
+		if err != nil {
+			return skipped, err
+		}
+
+		name := filepath.FromSlash(path.Clean(header.Name))
+		if !filepath.IsLocal(name) {
+			skipped = append(skipped, header.Name)
+			continue
+		}
+		target := filepath.Join(root, name)
+		// A directory of the archive, or one that already existed, may be a link out of root
+		if ok, err := withinRoot(root, filepath.Dir(target)); err != nil || !ok {
+			skipped = append(skipped, header.Name)
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, header.FileInfo().Mode().Perm()|0o700); err != nil {
+				return skipped, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return skipped, err
+			}
+			reporter.file = header.Name
+			if err := writeFile(target, archive, header.FileInfo().Mode().Perm(), reporter); err != nil {
+				return skipped, err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return skipped, err
+			}
+			if ok, err := symlinkWithinRoot(root, filepath.Dir(target), header.Linkname); err != nil || !ok {
+				skipped = append(skipped, header.Name)
+				continue
+			}
+			os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return skipped, err
+			}
+		default:
+			// Hard links, devices and fifos aren't needed to inspect files
+			skipped = append(skipped, header.Name)
+		}
+	}
+}
+
+// writeFile writes a copied file, replacing a link at its place instead of following it.
+func writeFile(target string, content io.Reader, perm fs.FileMode, reporter *copyReporter) error {
+	if info, err := os.Lstat(target); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm|0o600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.MultiWriter(file, reporter), content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// withinRoot tells whether dir, with its links resolved, is within root. Directories
+// that don't exist yet are created within their existing parent, which is checked instead.
+func withinRoot(root, dir string) (bool, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false, err
+	}
+	for {
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return isWithin(realRoot, realDir), nil
+		}
+		if !os.IsNotExist(err) || dir == root {
+			return false, err
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// symlinkWithinRoot tells whether a link in dir pointing to linkname stays within root.
+// Absolute links point into the container's filesystem and are never safe. A ".." after
+// another element is resolved by the OS after that element, which may itself be a link,
+// so only leading ".." elements are allowed, resolved from the real directory.
+func symlinkWithinRoot(root, dir, linkname string) (bool, error) {
+	if linkname == "" || filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") {
+		return false, nil
+	}
+	leading := true
+	for _, element := range strings.Split(linkname, "/") {
+		if element != ".." {
+			leading = false
+		} else if !leading {
+			return false, nil
+		}
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false, err
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	return isWithin(realRoot, filepath.Join(realDir, filepath.FromSlash(linkname))), nil
+}
+
+func isWithin(root, target string) bool {
+	rel, err := filepath.Rel(root, filepath.Clean(target))
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
+}
+
+// writeTar archives the local file or directory under its base name.
+func writeTar(writer io.Writer, localPath string, reporter *copyReporter) error {
+	archive := tar.NewWriter(writer)
+	parent := filepath.Dir(localPath)
+	err := filepath.WalkDir(localPath, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(parent, file)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		reporter.file = header.Name
+		_, err = io.Copy(io.MultiWriter(archive, reporter), content)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// localSize sums the sizes of the regular files of a file or directory.
+func localSize(localPath string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(localPath, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// limitedBuffer keeps the start of the stderr of a command for error messages.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := maxCopyStderr - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
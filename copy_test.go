@@ -0,0 +1,345 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	content  string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0o644}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0o755
+		}
+		if entry.typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.content))
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := archive.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+// copyTestDirs creates a root to extract into and a directory next to it with a secret
+// file that no archive may touch.
+func copyTestDirs(t *testing.T) (root, outside string) {
+	t.Helper()
+	dir := t.TempDir()
+	root = filepath.Join(dir, "root")
+	outside = filepath.Join(dir, "outside")
+	for _, d := range []string{filepath.Join(root, "sub"), outside} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root, outside
+}
+
+func discardReporter() *copyReporter {
+	return &copyReporter{emit: func(CopyEvent) {}}
+}
+
+func TestExtractTar(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, root, outside string)
+		entries     []tarEntry
+		wantSkipped []string
+		wantFiles   map[string]string // Contents read through links, relative to root
+		wantMissing []string          // Paths relative to root that mustn't exist
+	}{
+		{
+			name: "nested tree",
+			entries: []tarEntry{
+				{name: "app/", typeflag: tar.TypeDir},
+				{name: "app/conf/settings.yaml", typeflag: tar.TypeReg, content: "debug: true"},
+				{name: "app/current", typeflag: tar.TypeSymlink, linkname: "conf/settings.yaml"},
+				{name: "app/conf/parent", typeflag: tar.TypeSymlink, linkname: "../current"},
+				{name: "app/empty/", typeflag: tar.TypeDir},
+			},
+			wantFiles: map[string]string{
+				"app/conf/settings.yaml": "debug: true",
+				"app/current":            "debug: true",
+				"app/conf/parent":        "debug: true",
+			},
+		},
+		{
+			name: "parent directory entries",
+			entries: []tarEntry{
+				{name: "../escape", typeflag: tar.TypeReg, content: "x"},
+				{name: "app/../../escape", typeflag: tar.TypeReg, content: "x"},
+				{name: "../outside/secret", typeflag: tar.TypeReg, content: "overwritten"},
+				{name: "app/../kept", typeflag: tar.TypeReg, content: "kept"},
+			},
+			wantSkipped: []string{"../escape", "app/../../escape", "../outside/secret"},
+			wantFiles:   map[string]string{"kept": "kept"},
+		},
+		{
+			name: "absolute paths",
+			entries: []tarEntry{
+				{name: "/etc/escape", typeflag: tar.TypeReg, content: "x"},
+				{name: "/", typeflag: tar.TypeDir},
+				{name: "etc", typeflag: tar.TypeSymlink, linkname: "/etc"},
+			},
+			wantSkipped: []string{"/etc/escape", "/", "etc"},
+			wantMissing: []string{"etc"},
+		},
+		{
+			name: "symlink out of root, then a file through it",
+			entries: []tarEntry{
+				{name: "out", typeflag: tar.TypeSymlink, linkname: "../outside"},
+				{name: "out/secret", typeflag: tar.TypeReg, content: "overwritten"},
+			},
+			wantSkipped: []string{"out"},
+			// Without the link, the file lands in a new directory within root
+			wantFiles: map[string]string{"out/secret": "overwritten"},
+		},
+		{
+			name: "symlink out of root through a non-leading parent element",
+			entries: []tarEntry{
+				{name: "sub/link", typeflag: tar.TypeSymlink, linkname: "../sub/../../outside"},
+				{name: "sub/link/secret", typeflag: tar.TypeReg, content: "overwritten"},
+			},
+			wantSkipped: []string{"sub/link"},
+			wantFiles:   map[string]string{"sub/link/secret": "overwritten"},
+		},
+		{
+			name: "file through an existing link out of root",
+			setup: func(t *testing.T, root, outside string) {
+				if err := os.Symlink(outside, filepath.Join(root, "existing")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			entries: []tarEntry{
+				{name: "existing/secret", typeflag: tar.TypeReg, content: "overwritten"},
+				{name: "existing/new/file", typeflag: tar.TypeReg, content: "x"},
+			},
+			wantSkipped: []string{"existing/secret", "existing/new/file"},
+		},
+		{
+			name: "file replaces an existing link instead of following it",
+			setup: func(t *testing.T, root, outside string) {
+				if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "config")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			entries:   []tarEntry{{name: "config", typeflag: tar.TypeReg, content: "copied"}},
+			wantFiles: map[string]string{"config": "copied"},
+		},
+		{
+			name: "hard link escape",
+			entries: []tarEntry{
+				{name: "hard", typeflag: tar.TypeLink, linkname: "../outside/secret"},
+				{name: "fifo", typeflag: tar.TypeFifo},
+			},
+			wantSkipped: []string{"hard", "fifo"},
+			wantMissing: []string{"hard", "fifo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, outside := copyTestDirs(t)
+			if tt.setup != nil {
+				tt.setup(t, root, outside)
+			}
+
+			skipped, err := extractTar(buildTar(t, tt.entries), root, discardReporter())
+			if err != nil {
+				t.Fatalf("extractTar() error = %v", err)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %q, want %q", skipped, tt.wantSkipped)
+			}
+			for name, want := range tt.wantFiles {
+				got, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+				if err != nil || string(got) != want {
+					t.Errorf("%s = %q (%v), want %q", name, got, err, want)
+				}
+			}
+			for _, name := range tt.wantMissing {
+				if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(name))); !os.IsNotExist(err) {
+					t.Errorf("%s exists, want it skipped", name)
+				}
+			}
+
+			// Nothing outside of root changed
+			entries, err := os.ReadDir(outside)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != "secret" {
+				t.Errorf("outside of root = %v, want only the secret", entries)
+			}
+			if secret, _ := os.ReadFile(filepath.Join(outside, "secret")); string(secret) != "secret" {
+				t.Errorf("secret outside of root = %q, want it unchanged", secret)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(root)); len(entries) != 2 {
+				t.Errorf("next to root = %v, want only root and outside", entries)
+			}
+		})
+	}
+}
+
+func TestWithinRoot(t *testing.T) {
+	root, outside := copyTestDirs(t)
+	if err := os.Symlink("sub", filepath.Join(root, "in")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{dir: root, want: true},
+		{dir: filepath.Join(root, "sub"), want: true},
+		{dir: filepath.Join(root, "new", "deeper"), want: true},
+		{dir: filepath.Join(root, "in"), want: true},
+		{dir: filepath.Join(root, "in", "new"), want: true},
+		{dir: filepath.Join(root, "out"), want: false},
+		{dir: filepath.Join(root, "out", "new"), want: false},
+		{dir: outside, want: false},
+		{dir: filepath.Dir(root), want: false},
+	}
+	for _, tt := range tests {
+		name := strings.TrimPrefix(tt.dir, filepath.Dir(root))
+		t.Run(name, func(t *testing.T) {
+			got, err := withinRoot(root, tt.dir)
+			if err != nil && tt.want {
+				t.Fatalf("withinRoot() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("withinRoot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymlinkWithinRoot(t *testing.T) {
+	root, _ := copyTestDirs(t)
+	if err := os.Symlink("sub", filepath.Join(root, "in")); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "sub")
+
+	tests := []struct {
+		name     string
+		dir      string
+		linkname string
+		want     bool
+	}{
+		{name: "sibling", dir: sub, linkname: "file", want: true},
+		{name: "nested", dir: sub, linkname: "a/b/file", want: true},
+		{name: "parent within root", dir: sub, linkname: "../file", want: true},
+		{name: "root itself", dir: sub, linkname: "..", want: true},
+		{name: "parent of root", dir: sub, linkname: "../..", want: false},
+		{name: "outside of root", dir: sub, linkname: "../../outside/secret", want: false},
+		{name: "non-leading parent", dir: sub, linkname: "a/../../file", want: false},
+		{name: "absolute", dir: sub, linkname: "/etc/passwd", want: false},
+		{name: "empty", dir: sub, linkname: "", want: false},
+		// The parent of a linked directory is the parent of its target
+		{name: "parent through a link", dir: filepath.Join(root, "in"), linkname: "../file", want: true},
+		{name: "outside through a link", dir: filepath.Join(root, "in"), linkname: "../../file", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := symlinkWithinRoot(root, tt.dir, tt.linkname)
+			if err != nil {
+				t.Fatalf("symlinkWithinRoot() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("symlinkWithinRoot(%q) = %v, want %v", tt.linkname, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, target, outside string)
+		perm     fs.FileMode
+		wantPerm fs.FileMode
+	}{
+		{name: "new file", perm: 0o644, wantPerm: 0o644},
+		{name: "owner can always write", perm: 0o444, wantPerm: 0o644},
+		{
+			name: "existing file is truncated",
+			setup: func(t *testing.T, target, outside string) {
+				if err := os.WriteFile(target, []byte("a much longer previous content"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			perm:     0o644,
+			wantPerm: 0o600,
+		},
+		{
+			name: "link out of root is replaced",
+			setup: func(t *testing.T, target, outside string) {
+				if err := os.Symlink(filepath.Join(outside, "secret"), target); err != nil {
+					t.Fatal(err)
+				}
+			},
+			perm:     0o644,
+			wantPerm: 0o644,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, outside := copyTestDirs(t)
+			target := filepath.Join(root, "file")
+			if tt.setup != nil {
+				tt.setup(t, target, outside)
+			}
+
+			if err := writeFile(target, strings.NewReader("copied"), tt.perm, discardReporter()); err != nil {
+				t.Fatalf("writeFile() error = %v", err)
+			}
+			info, err := os.Lstat(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !info.Mode().IsRegular() {
+				t.Errorf("mode = %v, want a regular file", info.Mode())
+			}
+			// Group and other bits depend on the umask
+			if perm := info.Mode().Perm(); perm&0o700 != tt.wantPerm&0o700 {
+				t.Errorf("perm = %v, want %v", perm, tt.wantPerm)
+			}
+			if got, _ := os.ReadFile(target); string(got) != "copied" {
+				t.Errorf("content = %q, want %q", got, "copied")
+			}
+			if secret, _ := os.ReadFile(filepath.Join(outside, "secret")); string(secret) != "secret" {
+				t.Errorf("secret outside of root = %q, want it unchanged", secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	defaultDebugImage = "docker.io/library/busybox:1.36"
+	// debugContainerStartTimeout is the time an ephemeral container gets to pull its image and start.
+	debugContainerStartTimeout = 2 * time.Minute
+)
+
+// Debug profiles, like the ones of kubectl debug.
+const (
+	debugProfileGeneral  = "general"
+	debugProfileNetadmin = "netadmin"
+	debugProfileSysadmin = "sysadmin"
+)
+
+// DebugContainerOptions configures an ephemeral debug container. TargetContainerName
+// shares the process namespace of that container, so its processes can be inspected.
+// Profile sets the container's privileges: "general" (the default) can trace processes,
+// "netadmin" can capture and change network traffic and "sysadmin" is privileged.
+// An image like nicolaka/netshoot brings the tools for network debugging.
+type DebugContainerOptions struct {
+	Image               string `json:"image,omitempty"`
+	TargetContainerName string `json:"targetContainerName,omitempty"`
+	Profile             string `json:"profile,omitempty"`
+}
+
+// DebugContainer is a running ephemeral container, attach to it through the /terminal WebSocket.
+type DebugContainer struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+}
+
+// StartDebugContainer adds an ephemeral container with a TTY to the pod, like kubectl
+// debug -it, and waits until it runs. Ephemeral containers can't be removed, it stays
+// in the pod, terminated, once its shell exits.
+func (a *App) StartDebugContainer(clusterName, namespace, podName string, options DebugContainerOptions) (*DebugContainer, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), debugContainerStartTimeout)
+	defer cancel()
+
+	pods := clients.Clientset.CoreV1().Pods(namespace)
+	pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %q: %w", podName, err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("pod %q is not running (%s)", podName, pod.Status.Phase)
+	}
+	if options.TargetContainerName != "" && !hasContainer(pod, options.TargetContainerName) {
+		return nil, fmt.Errorf("pod %q has no container %q", podName, options.TargetContainerName)
+	}
+
+	container, err := debugContainer(pod, options)
+	if err != nil {
+		return nil, err
+	}
+	// The ephemeral containers are merged by name, so the patch only adds this one
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ephemeralContainers": []corev1.EphemeralContainer{container},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = pods.Patch(ctx, podName, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "ephemeralcontainers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to add debug container to pod %q: %w", podName, err)
+	}
+	log.Printf("Added debug container %s (%s) to pod %s/%s in %s", container.Name, container.Image, namespace, podName, clusterName)
+
+	if err := waitForDebugContainer(ctx, clients, namespace, podName, container.Name); err != nil {
+		return nil, err
+	}
+	return &DebugContainer{
+		Cluster:   clusterName,
+		Namespace: namespace,
+		Pod:       podName,
+		Container: container.Name,
+	}, nil
+}
+
+// debugContainer builds an interactive ephemeral container with a name that is unused in the pod.
+func debugContainer(pod *corev1.Pod, options DebugContainerOptions) (corev1.EphemeralContainer, error) {
+	image := options.Image
+	if image == "" {
+		image = defaultDebugImage
+	}
+
+	var securityContext *corev1.SecurityContext
+	switch options.Profile {
+	case "", debugProfileGeneral:
+		securityContext = &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_PTRACE"}},
+		}
+	case debugProfileNetadmin:
+		securityContext = &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"}},
+		}
+	case debugProfileSysadmin:
+		privileged := true
+		securityContext = &corev1.SecurityContext{Privileged: &privileged}
+	default:
+		return corev1.EphemeralContainer{}, fmt.Errorf("unknown debug profile %q", options.Profile)
+	}
+
+	name := "debugger-" + utilrand.String(5)
+	for hasContainer(pod, name) {
+		name = "debugger-" + utilrand.String(5)
+	}
+
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    image,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			SecurityContext:          securityContext,
+		},
+		TargetContainerName: options.TargetContainerName,
+	}, nil
+}
+
+// hasContainer tells whether the pod has a container, init or ephemeral container with the name.
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForDebugContainer waits until the ephemeral container runs, failing early if it can't start.
+func waitForDebugContainer(ctx context.Context, clients *KubeClients, namespace, podName, containerName string) error {
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		pod, err := clients.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != containerName {
+				continue
+			}
+			switch {
+			case status.State.Running != nil:
+				return true, nil
+			case status.State.Terminated != nil:
+				terminated := status.State.Terminated
+				return false, fmt.Errorf("debug container %q exited: %s %s", containerName, terminated.Reason, terminated.Message)
+			case status.State.Waiting != nil && isFatalWaitingReason(status.State.Waiting.Reason):
+				return false, fmt.Errorf("debug container %q can't start: %s: %s", containerName, status.State.Waiting.Reason, status.State.Waiting.Message)
+			}
+		}
+		return false, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("debug container %q didn't start within %s", containerName, debugContainerStartTimeout)
+	}
+	return err
+}
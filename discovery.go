@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/disk"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	discoveryCacheTTL = 10 * time.Minute
+	// discoveryMissGrace stops a lookup miss from invalidating a cache that was just refreshed.
+	discoveryMissGrace = 10 * time.Second
+	// discoveryCacheDirEnv enables persisting discovery to disk, like kubectl's ~/.kube/cache/discovery.
+	discoveryCacheDirEnv = "KUBEPLORER_DISCOVERY_CACHE_DIR"
+)
+
+// discoveryCache is the per-cluster API discovery shared by all resource lookups.
+type discoveryCache struct {
+	client    discovery.CachedDiscoveryInterface
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	ttl       time.Duration
+	mu        sync.Mutex
+	fetchedAt time.Time
+}
+
+func newDiscoveryCache(config *rest.Config, httpClient *http.Client) (*discoveryCache, error) {
+	if cacheDir := os.Getenv(discoveryCacheDirEnv); cacheDir != "" {
+		// The shared transport already does TLS and authentication, so credential plugins
+		// don't run again and connections are reused; the disk cache wraps it.
+		cacheConfig := &rest.Config{
+			Host:      config.Host,
+			APIPath:   config.APIPath,
+			UserAgent: config.UserAgent,
+			Timeout:   config.Timeout,
+			QPS:       config.QPS,
+			Burst:     config.Burst,
+			Transport: httpClient.Transport,
+		}
+		client, err := disk.NewCachedDiscoveryClientForConfig(
+			cacheConfig,
+			discoveryCacheDirFor(cacheDir, config.Host),
+			filepath.Join(cacheDir, "http"),
+			discoveryCacheTTL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create disk discovery cache: %w", err)
+		}
+		return newDiscoveryCacheFor(client), nil
+	}
+
+	client, err := discovery.NewDiscoveryClientForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return newDiscoveryCacheFor(memory.NewMemCacheClient(client)), nil
+}
+
+func newDiscoveryCacheFor(client discovery.CachedDiscoveryInterface) *discoveryCache {
+	return &discoveryCache{
+		client: client,
+		mapper: restmapper.NewDeferredDiscoveryRESTMapper(client),
+		ttl:    discoveryCacheTTL,
+	}
+}
+
+var unsafeCacheDirChars = regexp.MustCompile(`[^(\w/.)]`)
+
+// discoveryCacheDirFor mirrors kubectl's per-host layout of the discovery cache.
+func discoveryCacheDirFor(parentDir, host string) string {
+	schemelessHost := strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	return filepath.Join(parentDir, unsafeCacheDirChars.ReplaceAllString(schemelessHost, "_"))
+}
+
+// groupsAndResources returns cached discovery, refetching it once the TTL has passed.
+// Partial failures (e.g. an unavailable aggregated API) are logged and the rest is returned.
+func (d *discoveryCache) groupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	d.expireIfStale()
+
+	groups, resources, err := d.client.ServerGroupsAndResources()
+	if err != nil {
+		if discoveryErr, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+			log.Printf("Partial API group discovery failure: %v", discoveryErr.Groups)
+			return groups, resources, nil
+		}
+		return nil, nil, fmt.Errorf("failed to retrieve API resources: %w", err)
+	}
+	return groups, resources, nil
+}
+
+// restMapping maps a kind to its resource and scope, refreshing discovery once on a miss.
+func (d *discoveryCache) restMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	d.expireIfStale()
+
+	mapping, err := d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) && d.invalidateAfterMiss() {
+		log.Printf("Kind %s not found in cached discovery, refreshing", gvk)
+		mapping, err = d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
+func (d *discoveryCache) expireIfStale() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.fetchedAt.IsZero() && time.Since(d.fetchedAt) > d.ttl {
+		d.mapper.Reset()
+		d.fetchedAt = time.Time{}
+	}
+	if d.fetchedAt.IsZero() {
+		d.fetchedAt = time.Now()
+	}
+}
+
+// invalidate drops cached discovery so the next lookup goes to the API server.
+func (d *discoveryCache) invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mapper.Reset()
+	d.fetchedAt = time.Time{}
+}
+
+// invalidateAfterMiss invalidates the cache unless it was refreshed very recently.
+// It reports whether a retry against fresh discovery makes sense.
+func (d *discoveryCache) invalidateAfterMiss() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client.Fresh() && time.Since(d.fetchedAt) < discoveryMissGrace {
+		return false
+	}
+	d.mapper.Reset()
+	d.fetchedAt = time.Time{}
+	return true
+}
+
+// resourceQuery is a parsed resource argument as accepted by findResourceInfo.
+type resourceQuery struct {
+	resource string
+	group    string
+	version  string
+	hasGroup bool
+}
+
+var versionPattern = regexp.MustCompile(`^v\d+((alpha|beta)\d+)?$`)
+
+// parseResourceQuery accepts "pods", "deployments.apps", "deployments.v1.apps", "pods.v1",
+// "apps/v1/deployments", "apps/deployments" and "v1/pods".
+func parseResourceQuery(arg string) resourceQuery {
+	arg = strings.TrimSpace(arg)
+
+	if parts := strings.Split(arg, "/"); len(parts) == 3 {
+		return resourceQuery{resource: parts[2], group: parts[0], version: parts[1], hasGroup: true}
+	} else if len(parts) == 2 {
+		if versionPattern.MatchString(parts[0]) {
+			return resourceQuery{resource: parts[1], version: parts[0], hasGroup: true}
+		}
+		return resourceQuery{resource: parts[1], group: parts[0], hasGroup: true}
+	}
+
+	resource, rest, qualified := strings.Cut(arg, ".")
+	if !qualified {
+		return resourceQuery{resource: arg}
+	}
+	q := resourceQuery{resource: resource, group: rest, hasGroup: true}
+	if version, group, _ := strings.Cut(rest, "."); versionPattern.MatchString(version) {
+		q.version = version
+		q.group = group
+	}
+	return q
+}
+
+// resolveResource finds the resource matching arg in discovery. Within a group the server's
+// preferred version wins. A name served by several groups, e.g. events by the core group and
+// events.k8s.io, is reported as ambiguous with the qualified names to use instead.
+// found is false when nothing matches.
+func resolveResource(groups []*metav1.APIGroup, resourceLists []*metav1.APIResourceList, arg string) (info ResourceInfo, found bool, err error) {
+	q := parseResourceQuery(arg)
+
+	preferred := make(map[string]string)
+	for _, g := range groups {
+		preferred[g.Name] = g.PreferredVersion.Version
+	}
+
+	// Matches by plural or singular name take precedence over short names
+	var byName, byShortName []ResourceInfo
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if q.hasGroup && gv.Group != q.group {
+			continue
+		}
+		if q.version != "" && gv.Version != q.version {
+			continue
+		}
+
+		for _, r := range list.APIResources {
+			if !isBrowsableResource(gv, r) {
+				continue
+			}
+			singular := r.SingularName
+			if singular == "" {
+				singular = strings.ToLower(r.Kind)
+			}
+			switch {
+			case strings.EqualFold(r.Name, q.resource), strings.EqualFold(singular, q.resource):
+				byName = append(byName, newResourceInfo(gv, r))
+			case slices.ContainsFunc(r.ShortNames, func(s string) bool { return strings.EqualFold(s, q.resource) }):
+				byShortName = append(byShortName, newResourceInfo(gv, r))
+			}
+		}
+	}
+
+	candidates := byName
+	if len(candidates) == 0 {
+		candidates = byShortName
+	}
+	if len(candidates) == 0 {
+		return ResourceInfo{}, false, nil
+	}
+
+	// One candidate per group, preferring the server's preferred version
+	var perGroup []ResourceInfo
+	for _, c := range candidates {
+		i := slices.IndexFunc(perGroup, func(p ResourceInfo) bool { return p.Group == c.Group })
+		switch {
+		case i < 0:
+			perGroup = append(perGroup, c)
+		case c.Version == preferred[c.Group] && perGroup[i].Version != preferred[c.Group]:
+			perGroup[i] = c
+		}
+	}
+
+	if len(perGroup) == 1 {
+		return perGroup[0], true, nil
+	}
+
+	names := make([]string, 0, len(perGroup))
+	for _, p := range perGroup {
+		names = append(names, p.qualifiedName())
+	}
+	slices.Sort(names)
+	return ResourceInfo{}, false, fmt.Errorf("resource %q is ambiguous, use one of: %s", arg, strings.Join(names, ", "))
+}
+
+// RefreshApiResources drops the cached API discovery for the cluster.
+func (a *App) RefreshApiResources(clusterName string) error {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get clients: %w", err)
+	}
+	clients.discovery.invalidate()
+	return nil
+}
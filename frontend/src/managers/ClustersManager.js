@@ -1,4 +1,5 @@
 import {
+  DisconnectCluster,
   GetClusters,
   TestClusterConnectivity,
 } from "../../wailsjs/go/main/App.js";
@@ -241,6 +242,7 @@ export class ClustersManager {
       console.log(
         `Current cluster ${currentCluster} is disconnected, going back to cluster selection`,
       );
+      DisconnectCluster(currentCluster);
       this.app.goBackToClusterSelection();
     }
   }
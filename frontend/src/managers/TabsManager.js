@@ -126,6 +126,11 @@ export class TabsManager {
     tab.remove();
     this.tabs[tab.id].remove();
 
+    // Stop polling and watches of the tab's panels
+    const tabState = this.tabStates[tab.id];
+    tabState?.panels.forEach((panel) => panel.cleanup());
+    tabState?.stateManager.getUpdateManager().cleanup();
+
     // Clean up tab state
     delete this.tabStates[tab.id];
 
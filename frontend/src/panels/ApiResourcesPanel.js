@@ -175,13 +175,28 @@ export class ApiResourcesPanel extends StatefulPanel {
       throw new Error("Invalid API resources response");
     }
 
-    // Initialize a Set to store all unique resource apiResources
-    let allApiResources = new Set();
-
-    // Iterate over the API resources map
+    // Collect the API groups serving each resource name, with their version
+    const groupsByName = new Map();
     Object.values(apiResourcesMap).forEach((group) => {
-      // Add each api resource to the allApiResources Set
-      group.forEach((resource) => allApiResources.add(resource.Name));
+      group.forEach((resource) => {
+        if (!groupsByName.has(resource.Name)) {
+          groupsByName.set(resource.Name, new Map());
+        }
+        groupsByName.get(resource.Name).set(resource.Group, resource.Version);
+      });
+    });
+
+    // A name served by several groups is ambiguous for the backend, so each
+    // of them is qualified as "resource.group", the core group's as "events.v1"
+    let allApiResources = new Set();
+    groupsByName.forEach((groups, name) => {
+      if (groups.size === 1) {
+        allApiResources.add(name);
+        return;
+      }
+      groups.forEach((version, group) =>
+        allApiResources.add(`${name}.${group || version}`),
+      );
     });
 
     return Array.from(allApiResources);
@@ -1,5 +1,7 @@
 import { GetNamespaces, GetDefaultNamespace } from "../../wailsjs/go/main/App";
 import { StatefulPanel } from "./StatefulPanel.js";
+import { ALL_NAMESPACES } from "../utils/Config.js";
+import { Utils } from "../utils/Utils.js";
 
 export class NamespacesPanel extends StatefulPanel {
   constructor(name, container, tab, stateManager = null) {
@@ -90,15 +92,21 @@ export class NamespacesPanel extends StatefulPanel {
     // Например, добавить класс или показать маленький спиннер
   }
 
+  namespaceLabel(namespace) {
+    return namespace === ALL_NAMESPACES
+      ? Utils.translate("All namespaces")
+      : namespace;
+  }
+
   updateUI(selectedNamespace, namespaces = null, searchValue = null) {
-    this.selectedElText = selectedNamespace;
-    this.header2ValueEl.textContent = selectedNamespace;
+    this.selectedElText = this.namespaceLabel(selectedNamespace);
+    this.header2ValueEl.textContent = this.namespaceLabel(selectedNamespace);
 
     // Если переданы namespaces, перерендериваем весь список
     if (namespaces) {
-      const options = namespaces.map(
+      const options = [ALL_NAMESPACES, ...namespaces].map(
         (namespace) =>
-          `<div class="${this.listItemClass} ${namespace === selectedNamespace ? "selected" : ""}">${namespace}</div>`,
+          `<div class="${this.listItemClass} ${namespace === selectedNamespace ? "selected" : ""}" data-namespace="${namespace}">${this.namespaceLabel(namespace)}</div>`,
       );
       this.listEl.innerHTML = options.join("");
     } else {
@@ -107,7 +115,7 @@ export class NamespacesPanel extends StatefulPanel {
       allItems.forEach((item) => {
         item.classList.toggle(
           "selected",
-          item.textContent === selectedNamespace,
+          item.dataset.namespace === selectedNamespace,
         );
       });
     }
@@ -134,7 +142,11 @@ export class NamespacesPanel extends StatefulPanel {
   async resolveSelectedNamespace(namespaces) {
     const currentSelection = this.stateManager.getState("selectedNamespace");
 
-    if (currentSelection && namespaces.includes(currentSelection)) {
+    if (
+      currentSelection &&
+      (currentSelection === ALL_NAMESPACES ||
+        namespaces.includes(currentSelection))
+    ) {
       return currentSelection;
     }
 
@@ -191,7 +203,10 @@ export class NamespacesPanel extends StatefulPanel {
     const newSelection = super.select(event);
     if (!newSelection) return;
 
-    this.stateManager?.setState("selectedNamespace", newSelection.textContent);
+    this.stateManager?.setState(
+      "selectedNamespace",
+      newSelection.dataset.namespace,
+    );
     this.header2ValueEl.textContent = newSelection.textContent;
     this.setState(this.PANEL_STATES.SELECTED);
   }
@@ -1,17 +1,28 @@
 import {
   GetResourcesInNamespace,
+  SubscribeResources,
+  UnsubscribeResources,
+  ValidateSelectors,
+  GetResourceTable,
+  GetResourceTableRow,
   ApplyResource,
   DeleteResource,
 } from "../../wailsjs/go/main/App";
+import { EventsOn } from "../../wailsjs/runtime/runtime.js";
 import { DependencyGraph } from "../components/DependencyGraph.js";
 import { SecretResource } from "../resources/SecretResource";
 import { PodResource } from "../resources/PodResource";
+import { WorkloadResource } from "../resources/WorkloadResource";
+import { NodeResource } from "../resources/NodeResource";
 import { Resource } from "../resources/Resource";
 import { Panel } from "./Panel";
 import { Utils } from "../utils/Utils";
-import { RESOURCE_COLUMNS } from "../utils/Config.js";
+import { RESOURCE_COLUMNS, ALL_NAMESPACES } from "../utils/Config.js";
 import { ModalWindow } from "../windows/ModalWindow.js";
 
+// Rows per page when listing the table columns of a list
+const TABLE_PAGE_SIZE = 500;
+
 export class ResourcesPanel extends Panel {
   constructor(name, container, tab, stateManager = null) {
     super(name, container, tab, stateManager);
@@ -38,11 +49,18 @@ export class ResourcesPanel extends Panel {
     }
     this.currentUpdateAbortController = null;
     this.updateInterval = null;
+    this.subscription = null;
+    this.stopDeltas = null;
+    this.tableColumns = null;
+    this.tableIndexes = [];
+    this.table = null;
+    this.tableRefreshTimeout = null;
     this.buttonEl = container.querySelector(".create-resource-btn");
     this.buttonFunction = () => this.showCreateResourceModal();
     this.listHeadersEl = null;
     this.optColumns = null;
     this.createListHeaders();
+    this.createSelectorInputs();
     this.monacoModal = null;
     this.deleteBtn = Utils.createEl("floating-btn", "", "button");
     this.deleteBtn.append(Utils.createIconEl("fa-trash"));
@@ -88,9 +106,96 @@ export class ResourcesPanel extends Panel {
     resourceHeader.append(nameContainerEl, this.optColumns, ageAndActionsEl);
   }
 
+  // Inputs for server-side label and field selectors
+  createSelectorInputs() {
+    this.selectorsEl = Utils.createEl("selectors-wrapper");
+    this.labelSelectorEl = Utils.createInputEl(
+      "search-input selector-input",
+      Utils.translate("Label selector") + " (app=web,tier in (db))",
+    );
+    this.fieldSelectorEl = Utils.createInputEl(
+      "search-input selector-input",
+      Utils.translate("Field selector") + " (status.phase!=Running)",
+    );
+    this.selectorsEl.append(this.labelSelectorEl, this.fieldSelectorEl);
+    this.panelEl.insertBefore(this.selectorsEl, this.listHeadersEl);
+  }
+
+  // Selectors are saved per resource type
+  static loadSelectors() {
+    try {
+      return JSON.parse(localStorage.getItem("resource-selectors")) || {};
+    } catch (error) {
+      console.error("Error loading selectors:", error);
+      return {};
+    }
+  }
+
+  selectorsFor(apiResource) {
+    return ResourcesPanel.loadSelectors()[apiResource] || {};
+  }
+
+  saveSelectors(apiResource, selectors) {
+    const saved = ResourcesPanel.loadSelectors();
+    if (selectors.labelSelector || selectors.fieldSelector) {
+      saved[apiResource] = selectors;
+    } else {
+      delete saved[apiResource];
+    }
+    localStorage.setItem("resource-selectors", JSON.stringify(saved));
+  }
+
+  showSelectors(apiResource) {
+    const selectors = this.selectorsFor(apiResource);
+    this.labelSelectorEl.value = selectors.labelSelector || "";
+    this.fieldSelectorEl.value = selectors.fieldSelector || "";
+    this.markSelectorErrors([]);
+  }
+
+  markSelectorErrors(errors) {
+    for (const [kind, inputEl] of [
+      ["label", this.labelSelectorEl],
+      ["field", this.fieldSelectorEl],
+    ]) {
+      const error = errors.find((e) => e.selector === kind);
+      inputEl.classList.toggle("invalid", Boolean(error));
+      inputEl.title = error
+        ? `${Utils.translate("Error at position")} ${error.position + 1}: ${error.message}`
+        : "";
+      if (error) {
+        inputEl.focus();
+        inputEl.setSelectionRange(error.position, inputEl.value.length);
+      }
+    }
+  }
+
+  async applySelectors() {
+    const apiResource = this.stateManager.getState("selectedApiResource");
+    if (!apiResource) return;
+
+    const selectors = {
+      labelSelector: this.labelSelectorEl.value.trim(),
+      fieldSelector: this.fieldSelectorEl.value.trim(),
+    };
+    const errors = (await ValidateSelectors(selectors)) || [];
+    this.markSelectorErrors(errors);
+    if (errors.length) return;
+
+    this.saveSelectors(apiResource, selectors);
+    this.scheduleUpdate();
+  }
+
   setupEventListeners() {
     super.setupEventListeners();
 
+    for (const inputEl of [this.labelSelectorEl, this.fieldSelectorEl]) {
+      inputEl.addEventListener("keydown", (event) => {
+        if (event.key === "Enter") {
+          this.applySelectors();
+        }
+      });
+    }
+
     this.deleteBtn.addEventListener("click", async () => {
       if (!confirm(`Are you sure you want to delete selected resources?`))
         return;
@@ -103,11 +208,12 @@ export class ResourcesPanel extends Panel {
       const checkedBoxes = this.tab.querySelectorAll(".checkboxItem:checked");
 
       for (const checkboxEl of checkedBoxes) {
+        const item = checkboxEl.closest(".item");
         await DeleteResource(
           this.cluster,
-          selectedNamespace,
+          item.dataset.resourceNamespace || selectedNamespace,
           apiResource,
-          checkboxEl.nextElementSibling.textContent,
+          item.dataset.resourceName,
         );
         checkboxEl.checked = false;
       }
@@ -154,10 +260,13 @@ export class ResourcesPanel extends Panel {
     this.selectedResource = resourceItem;
 
     // Показать dependency graph
-    this.showDependencyGraph(resourceItem.dataset.resourceName);
+    this.showDependencyGraph(
+      resourceItem.dataset.resourceName,
+      resourceItem.dataset.resourceNamespace,
+    );
   }
 
-  async showDependencyGraph(resourceName) {
+  async showDependencyGraph(resourceName, resourceNamespace = "") {
     // Очистить предыдущий граф
     if (this.currentDependencyGraph) {
       this.currentDependencyGraph.clear();
@@ -189,7 +298,7 @@ export class ResourcesPanel extends Panel {
     this.currentDependencyGraph = new DependencyGraph(
       contentContainer,
       this.cluster,
-      this.stateManager.getState("selectedNamespace"),
+      resourceNamespace || this.stateManager.getState("selectedNamespace"),
       this.stateManager.getState("selectedApiResource"),
       resourceName,
       navigationCallback,
@@ -213,6 +322,7 @@ export class ResourcesPanel extends Panel {
   clear() {
     super.clear();
     this.listHeadersEl.remove();
+    this.selectorsEl.remove();
     if (this.deleteBtn && this.deleteBtn.parentNode) {
       this.deleteBtn.remove();
     }
@@ -276,20 +386,27 @@ export class ResourcesPanel extends Panel {
       console.log("No cluster selected, skipping update");
       return;
     }
-    const panelId = `${this.cluster}-${selectedNamespace}-${apiResource}`;
+    const selectors = this.selectorsFor(apiResource);
+    const panelId = `${this.cluster}-${selectedNamespace}-${apiResource}-${selectors.labelSelector || ""}-${selectors.fieldSelector || ""}`;
     if (this.currentPanelId === panelId) return;
 
     this.header1ValueEl.textContent = apiResource;
     this.updateHeader(apiResource);
+    this.showSelectors(apiResource);
 
     this.cleanup();
     // Show loading state
-    this.listEl.innerHTML = `<div class="no-resources">Loading ${apiResource} in namespace ${selectedNamespace}...</div>`;
+    this.listEl.innerHTML = `<div class="no-resources">Loading ${apiResource} in ${this.namespaceLabel(selectedNamespace)}...</div>`;
 
-    await this.updateHtml();
+    if (await this.subscribe(selectedNamespace, apiResource, selectors)) {
+      // Changes arrive as watch events, only ages need a refresh
+      this.registerForUpdates(panelId, () => this.refreshAges(), 1000);
+    } else {
+      await this.updateHtml();
 
-    // Set up the interval to try updating every 1 second
-    this.registerForUpdates(panelId, () => this.updateWithTimeout(), 1000);
+      // Fall back to polling every 1 second
+      this.registerForUpdates(panelId, () => this.updateWithTimeout(), 1000);
+    }
 
     // Сохраняем panelId для последующей очистки
     this.currentPanelId = panelId;
@@ -336,35 +453,13 @@ export class ResourcesPanel extends Panel {
         this.cluster,
         apiResource,
         selectedNamespace,
+        this.selectorsFor(apiResource),
       );
 
       // Check for abort after async operations
       this.checkAbort(signal);
 
-      if (!resources?.length) {
-        this.listEl.innerHTML = `<div class="no-resources">No ${apiResource} in ${selectedNamespace}</div>`;
-        this.updateStatistics();
-        return;
-      }
-
-      this.removeNoResourcesElement();
-      this.checkAbort(signal);
-
-      const resourceItems = this.getAllListElements();
-      this.removeStaleResources(resourceItems, resources, signal);
-      this.checkAbort(signal);
-
-      this.processResources(
-        selectedNamespace,
-        apiResource,
-        resources,
-        resourceItems,
-        signal,
-      );
-      this.checkAbort(signal);
-
-      this.search();
-      this.updateStatistics();
+      this.renderResources(selectedNamespace, apiResource, resources, signal);
     } catch (error) {
       if (error.name !== "AbortError") {
         // Обрабатываем строковые ошибки
@@ -387,6 +482,319 @@ export class ResourcesPanel extends Panel {
     }
   }
 
+  renderResources(
+    namespace,
+    apiResource,
+    resources,
+    signal = new AbortController().signal,
+  ) {
+    if (!resources?.length) {
+      this.listEl.innerHTML = `<div class="no-resources">No ${apiResource} in ${this.namespaceLabel(namespace)}</div>`;
+      this.updateStatistics();
+      return;
+    }
+
+    this.removeNoResourcesElement();
+    this.checkAbort(signal);
+
+    const resourceItems = this.getAllListElements();
+    this.removeStaleResources(resourceItems, resources, signal);
+    this.checkAbort(signal);
+
+    this.processResources(
+      namespace,
+      apiResource,
+      resources,
+      resourceItems,
+      signal,
+    );
+    this.checkAbort(signal);
+
+    this.search();
+    this.updateStatistics();
+    this.scheduleTableRefresh(namespace, apiResource);
+  }
+
+  // Kinds without their own columns get the printer columns of `kubectl get`
+  usesTableColumns(apiResource) {
+    return !RESOURCE_COLUMNS[Utils.coreApiResource(apiResource)];
+  }
+
+  // The table is listed once per list, in pages. Afterwards only the rows of
+  // resources whose resourceVersion changed are fetched again.
+  scheduleTableRefresh(namespace, apiResource) {
+    if (!this.usesTableColumns(apiResource)) {
+      return;
+    }
+    if (!this.table) {
+      this.table = { namespace, apiResource, rows: new Map(), loaded: false };
+      this.loadTable(this.table);
+      return;
+    }
+    if (this.table.refreshing) {
+      // Checked again once the running refresh is done
+      this.table.dirty = true;
+      return;
+    }
+    if (!this.table.loaded || this.tableRefreshTimeout) {
+      return;
+    }
+    this.tableRefreshTimeout = setTimeout(async () => {
+      this.tableRefreshTimeout = null;
+      const table = this.table;
+      if (!table) return;
+
+      table.refreshing = true;
+      try {
+        await this.refreshTableRows(table);
+      } finally {
+        table.refreshing = false;
+      }
+      if (table.dirty && this.table === table) {
+        table.dirty = false;
+        this.scheduleTableRefresh(table.namespace, table.apiResource);
+      }
+    }, 1000);
+  }
+
+  async loadTable(table) {
+    let continueToken = "";
+    do {
+      let page;
+      try {
+        page = await GetResourceTable(
+          this.cluster,
+          table.apiResource,
+          table.namespace,
+          {
+            ...this.selectorsFor(table.apiResource),
+            limit: TABLE_PAGE_SIZE,
+            continue: continueToken,
+          },
+        );
+      } catch (error) {
+        console.warn(
+          `Failed to get table columns of ${table.apiResource}:`,
+          error,
+        );
+        return;
+      }
+      // The list may have changed while the page was loading
+      if (this.table !== table) return;
+
+      this.setTableColumns(page.columns);
+      page.rows.forEach((row) => table.rows.set(this.rowKey(row), row));
+      continueToken = page.continue;
+    } while (continueToken);
+
+    table.loaded = true;
+    this.applyTableRows(table);
+    // Resources that changed while the pages were loading
+    this.scheduleTableRefresh(table.namespace, table.apiResource);
+  }
+
+  // Fetches the rows of resources that were added or changed since their row was fetched
+  async refreshTableRows(table) {
+    const items = this.getAllListElements();
+    const keys = new Set(items.map((item) => this.itemKey(item)));
+    for (const key of table.rows.keys()) {
+      if (!keys.has(key)) table.rows.delete(key);
+    }
+
+    const stale = items.filter(
+      (item) =>
+        table.rows.get(this.itemKey(item))?.resourceVersion !==
+        item.dataset.resourceVersion,
+    );
+    if (stale.length > TABLE_PAGE_SIZE / 10) {
+      // Cheaper to list again than to get that many rows one by one
+      this.table = null;
+      this.scheduleTableRefresh(table.namespace, table.apiResource);
+      return;
+    }
+
+    await Promise.all(
+      stale.map(async (item) => {
+        let rowTable;
+        try {
+          rowTable = await GetResourceTableRow(
+            this.cluster,
+            table.apiResource,
+            item.dataset.resourceNamespace,
+            item.dataset.resourceName,
+          );
+        } catch (error) {
+          // Deleted meanwhile, the watch removes it from the list
+          console.warn(
+            `Failed to get table row of ${item.dataset.resourceName}:`,
+            error,
+          );
+          return;
+        }
+        if (this.table !== table || !rowTable.rows.length) return;
+        table.rows.set(this.itemKey(item), rowTable.rows[0]);
+      }),
+    );
+    if (this.table === table) {
+      this.applyTableRows(table);
+    }
+  }
+
+  setTableColumns(columns) {
+    // Name and age are always shown, wide columns are skipped like kubectl does
+    this.tableIndexes = columns
+      .map((column, index) => ({ column, index }))
+      .filter(
+        ({ column }) =>
+          column.priority === 0 &&
+          column.name !== "Name" &&
+          column.name !== "Age" &&
+          column.name !== "Namespace",
+      );
+
+    const names = this.tableIndexes.map(({ column }) => column.name);
+    if (JSON.stringify(names) !== JSON.stringify(this.tableColumns)) {
+      this.tableColumns = names;
+      this.optColumns.innerHTML = "";
+      this.tableIndexes.forEach(({ column }) => {
+        const columnEl = Utils.createEl("table-cell", column.name);
+        columnEl.title = column.description || "";
+        this.optColumns.appendChild(columnEl);
+      });
+    }
+  }
+
+  applyTableRows(table) {
+    for (const item of this.getAllListElements()) {
+      const row = table.rows.get(this.itemKey(item));
+      const columnsEl = item.querySelector(".optional-columns");
+      if (
+        !row ||
+        !columnsEl ||
+        item.dataset.tableVersion === row.resourceVersion
+      ) {
+        continue;
+      }
+
+      item.dataset.tableVersion = row.resourceVersion ?? "";
+      columnsEl.innerHTML = "";
+      this.tableIndexes.forEach(({ column, index }) => {
+        const value = row.cells[index] ?? "";
+        const cellEl = Utils.createEl("table-cell", String(value));
+        cellEl.dataset.type = column.type;
+        columnsEl.appendChild(cellEl);
+      });
+    }
+  }
+
+  rowKey(row) {
+    return `${row.namespace ?? ""}/${row.name}`;
+  }
+
+  itemKey(item) {
+    return `${item.dataset.resourceNamespace}/${item.dataset.resourceName}`;
+  }
+
+  // Subscribes to watch events of the list. Returns false if watching is not
+  // possible (e.g. no watch permission) and the list has to be polled.
+  async subscribe(namespace, apiResource, selectors) {
+    let subscription;
+    try {
+      subscription = await SubscribeResources(
+        this.cluster,
+        apiResource,
+        namespace,
+        selectors,
+      );
+    } catch (error) {
+      console.warn(`Watching ${apiResource} failed, polling instead:`, error);
+      return false;
+    }
+
+    // The selection may have changed while the list was loading
+    if (
+      this.stateManager.getState("selectedNamespace") !== namespace ||
+      this.stateManager.getState("selectedApiResource") !== apiResource
+    ) {
+      UnsubscribeResources(subscription.id);
+      return true;
+    }
+
+    this.unsubscribe();
+    this.subscription = subscription;
+    this.stopDeltas = EventsOn(subscription.event, (deltas) =>
+      this.applyDeltas(namespace, apiResource, deltas),
+    );
+    this.renderResources(namespace, apiResource, subscription.items);
+    return true;
+  }
+
+  unsubscribe() {
+    if (this.stopDeltas) {
+      this.stopDeltas();
+      this.stopDeltas = null;
+    }
+    if (this.subscription) {
+      UnsubscribeResources(this.subscription.id);
+      this.subscription = null;
+    }
+  }
+
+  applyDeltas(namespace, apiResource, deltas) {
+    const resourceItems = this.getAllListElements();
+    for (const delta of deltas) {
+      const existingItem = this.findItem(resourceItems, delta);
+      if (delta.type === "deleted") {
+        existingItem?.remove();
+      } else if (existingItem) {
+        this.updateExistingResource(existingItem, delta.resource, apiResource);
+      } else {
+        this.removeNoResourcesElement();
+        this.addNewResource(namespace, apiResource, delta.resource);
+        resourceItems.push(this.listEl.firstElementChild);
+      }
+    }
+
+    if (!this.getAllListElements().length) {
+      this.listEl.innerHTML = `<div class="no-resources">No ${apiResource} in ${this.namespaceLabel(namespace)}</div>`;
+    }
+    this.search();
+    this.updateStatistics();
+    this.scheduleTableRefresh(namespace, apiResource);
+  }
+
+  // Items are identified by namespace and name, names repeat across namespaces
+  findItem(items, resource) {
+    return items.find(
+      (item) =>
+        item.dataset.resourceName === resource.name &&
+        item.dataset.resourceNamespace === (resource.namespace ?? ""),
+    );
+  }
+
+  namespaceLabel(namespace) {
+    return namespace === ALL_NAMESPACES
+      ? Utils.translate("all namespaces")
+      : `namespace ${namespace}`;
+  }
+
+  refreshAges() {
+    for (const item of this.getAllListElements()) {
+      const ageEl = item.querySelector(".resource-age");
+      if (ageEl && item.dataset.created) {
+        ageEl.textContent = Utils.formatAge(item.dataset.created);
+      }
+    }
+  }
+
+  cleanup() {
+    super.cleanup();
+    this.unsubscribe();
+    this.table = null;
+    clearTimeout(this.tableRefreshTimeout);
+    this.tableRefreshTimeout = null;
+  }
+
   // Helper method to check for abort
   checkAbort(signal) {
     if (signal.aborted) {
@@ -403,7 +811,7 @@ export class ResourcesPanel extends Panel {
     if (!resourceItems) return;
 
     const toRemove = resourceItems.filter(
-      (item) => !resources.some((r) => r.name === item.dataset.resourceName),
+      (item) => !resources.some((r) => this.findItem([item], r)),
     );
 
     this.checkAbort(signal);
@@ -414,9 +822,7 @@ export class ResourcesPanel extends Panel {
     for (const resource of resources) {
       this.checkAbort(signal);
 
-      const existingItem = existingItems.find(
-        (item) => item.dataset.resourceName === resource.name,
-      );
+      const existingItem = this.findItem(existingItems, resource);
 
       if (existingItem) {
         this.updateExistingResource(existingItem, resource, apiResource);
@@ -427,7 +833,7 @@ export class ResourcesPanel extends Panel {
   }
 
   updateExistingResource(item, resource, apiResource) {
-    const columns = RESOURCE_COLUMNS[apiResource] || [];
+    const columns = RESOURCE_COLUMNS[Utils.coreApiResource(apiResource)] || [];
     [...columns.map((col) => col.key), "age"].forEach((field) => {
       const element = item.querySelector(`.resource-${field}`);
       const value = resource[field] ?? resource.spec?.[field] ?? "";
@@ -436,6 +842,7 @@ export class ResourcesPanel extends Panel {
         element.dataset[field] = value;
       }
     });
+    item.dataset.resourceVersion = resource.metadata?.resourceVersion ?? "";
   }
 
   addNewResource(namespace, apiResource, resource) {
@@ -445,7 +852,17 @@ export class ResourcesPanel extends Panel {
   }
 
   createResource(namespace, apiResource, resource) {
-    switch (apiResource) {
+    const item = this.createResourceItem(
+      resource.namespace || namespace,
+      apiResource,
+      resource,
+    );
+    item.showNamespace = namespace === ALL_NAMESPACES;
+    return item;
+  }
+
+  createResourceItem(namespace, apiResource, resource) {
+    switch (Utils.coreApiResource(apiResource)) {
       case "pods":
         return new PodResource(
           this.tab,
@@ -454,6 +871,27 @@ export class ResourcesPanel extends Panel {
           apiResource,
           resource,
         );
+      case "deployments":
+      case "statefulsets":
+      case "daemonsets":
+      case "replicasets":
+      case "jobs":
+      case "services":
+        return new WorkloadResource(
+          this.tab,
+          this.cluster,
+          namespace,
+          apiResource,
+          resource,
+        );
+      case "nodes":
+        return new NodeResource(
+          this.tab,
+          this.cluster,
+          namespace,
+          apiResource,
+          resource,
+        );
       case "secrets":
         return new SecretResource(
           this.tab,
@@ -507,8 +945,9 @@ export class ResourcesPanel extends Panel {
 
   updateHeader(apiResource) {
     this.optColumns.innerHTML = ""; // Clear existing columns
+    this.tableColumns = null;
 
-    const columns = RESOURCE_COLUMNS[apiResource] || [];
+    const columns = RESOURCE_COLUMNS[Utils.coreApiResource(apiResource)] || [];
 
     columns.forEach((column) => {
       const columnEl = Utils.createEl(
@@ -0,0 +1,105 @@
+import { StartNodeShell } from "../../wailsjs/go/main/App.js";
+
+import { Resource } from "./Resource";
+import { Utils } from "../utils/Utils.js";
+import { TerminalWindow } from "../windows/TerminalWindow.js";
+
+import "@fortawesome/fontawesome-free/css/all.css";
+
+// Node shell pod settings, e.g. an image from a local registry for air-gapped clusters
+const shellOptionsKey = "node-shell-options";
+
+export class NodeResource extends Resource {
+  constructor(tab, cluster, namespace, apiResource, resource) {
+    super(tab, cluster, namespace, apiResource, resource);
+    this.extraActions = {
+      Shell: () => this.openShell(),
+      "Shell settings": () => this.editShellOptions(),
+    };
+  }
+
+  static loadShellOptions() {
+    try {
+      return JSON.parse(localStorage.getItem(shellOptionsKey)) || {};
+    } catch {
+      return {};
+    }
+  }
+
+  async openShell() {
+    let shell;
+    try {
+      Utils.showLoadingIndicator(
+        Utils.translate("Starting node shell"),
+        this.tab,
+      );
+      shell = await StartNodeShell(
+        this.cluster,
+        this.resource.name,
+        NodeResource.loadShellOptions(),
+      );
+    } catch (error) {
+      console.error(`Failed to start shell on ${this.resource.name}:`, error);
+      alert(`${Utils.translate("Shell")} ${this.resource.name}: ${error}`);
+      return;
+    } finally {
+      Utils.hideLoadingIndicator(this.tab);
+    }
+
+    // The backend deletes the pod when the terminal session ends
+    new TerminalWindow(
+      this.tab,
+      Utils.translate("Shell") + ` - ${this.cluster}/${this.resource.name}`,
+      {
+        cluster: shell.cluster,
+        namespace: shell.namespace,
+        pod: shell.pod,
+        container: shell.container,
+        command: shell.command,
+      },
+    );
+  }
+
+  editShellOptions() {
+    const options = NodeResource.loadShellOptions();
+
+    const image = prompt(
+      Utils.translate("Node shell image (empty for busybox)"),
+      options.image || "",
+    );
+    if (image === null) return;
+    const namespace = prompt(
+      Utils.translate("Node shell namespace"),
+      options.namespace || "kube-system",
+    );
+    if (namespace === null) return;
+    const tolerationsInput = prompt(
+      Utils.translate("Tolerations as JSON (empty tolerates all taints)"),
+      options.tolerations ? JSON.stringify(options.tolerations) : "",
+    );
+    if (tolerationsInput === null) return;
+
+    let tolerations;
+    if (tolerationsInput.trim()) {
+      try {
+        tolerations = JSON.parse(tolerationsInput);
+      } catch (error) {
+        alert(`${Utils.translate("Invalid JSON")}: ${error.message}`);
+        return;
+      }
+      if (!Array.isArray(tolerations)) {
+        alert(Utils.translate("Tolerations must be a JSON array"));
+        return;
+      }
+    }
+
+    localStorage.setItem(
+      shellOptionsKey,
+      JSON.stringify({
+        image: image.trim() || undefined,
+        namespace: namespace.trim() || undefined,
+        tolerations,
+      }),
+    );
+  }
+}
@@ -1,7 +1,15 @@
-import { GetPodContainerLogs } from "../../wailsjs/go/main/App.js";
+import {
+  GetPodContainerLogs,
+  StartDebugContainer,
+  CopyFromPod,
+  CopyToPod,
+  CancelCopy,
+} from "../../wailsjs/go/main/App.js";
+import { EventsOn } from "../../wailsjs/runtime/runtime.js";
 
 import { Resource } from "./Resource";
 import { ModalWindow } from "../windows/ModalWindow.js";
+import { TerminalWindow } from "../windows/TerminalWindow.js";
 import { Utils } from "../utils/Utils.js";
 
 import "@fortawesome/fontawesome-free/css/all.css";
@@ -11,6 +19,10 @@ import { FitAddon } from "@xterm/addon-fit";
 import "@xterm/xterm/css/xterm.css";
 import { marked } from "marked";
 
+// Last used debug container settings
+const debugOptionsKey = "debug-container-options";
+const debugProfiles = ["general", "netadmin", "sysadmin"];
+
 marked.setOptions({
   breaks: true, // Convert \n to <br>
   gfm: true, // GitHub Flavored Markdown
@@ -21,7 +33,12 @@ export class PodResource extends Resource {
     super(tab, cluster, namespace, apiResource, resource);
     this.extraActions = {
       Logs: (event) => this.openLogs(event, this.actionButtonsEl),
+      "Live logs": (event) => this.openLiveLogs(event, this.actionButtonsEl),
       Terminal: (event) => this.openTerminal(event, this.actionButtonsEl),
+      Attach: (event) => this.openAttach(event, this.actionButtonsEl),
+      "Port forward": () => this.portForward(),
+      Debug: () => this.debug(),
+      "Copy files": () => this.copyFiles(),
     };
     if (this.resource.containers.includes("istio-proxy")) {
       this.extraActions["Istio config"] = () =>
@@ -50,6 +67,14 @@ export class PodResource extends Resource {
     this.connectToTerminal(this.resource.containers[0]);
   }
 
+  async openAttach(event, resourceItem) {
+    if (this.resource.containers.length > 1) {
+      this.setupDropdown(event, resourceItem, this.attach.bind(this));
+      return;
+    }
+    this.attach(this.resource.containers[0]);
+  }
+
   async openLogs(event, resourceItem) {
     if (this.resource.containers.length > 1) {
       this.setupDropdown(event, resourceItem, this.viewLogs.bind(this));
@@ -58,6 +83,14 @@ export class PodResource extends Resource {
     this.viewLogs(this.resource.containers[0]);
   }
 
+  async openLiveLogs(event, resourceItem) {
+    if (this.resource.containers.length > 1) {
+      this.setupDropdown(event, resourceItem, this.streamLogs.bind(this));
+      return;
+    }
+    this.streamLogs(this.resource.containers[0]);
+  }
+
   setupDropdown(event, resourceItem, actionHandler) {
     event.stopPropagation();
     const dropdown = this.createDropdown(actionHandler);
@@ -93,90 +126,232 @@ export class PodResource extends Resource {
     const title =
       Utils.translate("Terminal") +
       ` - ${this.cluster}/${this.namespace}/${this.resource.name}/${containerName}`;
-    const modalContent = `<div id="terminal"></div>`;
-    new ModalWindow(this.tab, modalContent, "terminal-content", title);
+    new TerminalWindow(this.tab, title, {
+      cluster: this.cluster,
+      namespace: this.namespace,
+      pod: this.resource.name,
+      container: containerName,
+      command: "/bin/sh",
+    });
+  }
 
-    const terminal = new Terminal({
-      cursorBlink: true,
-      fontSize: 20,
-      fontFamily: "Courier New",
+  // Connects to the running process of the container, like kubectl attach
+  attach(containerName) {
+    const title =
+      Utils.translate("Attach") +
+      ` - ${this.cluster}/${this.namespace}/${this.resource.name}/${containerName}`;
+    new TerminalWindow(this.tab, title, {
+      cluster: this.cluster,
+      namespace: this.namespace,
+      pod: this.resource.name,
+      container: containerName,
+      mode: "attach",
     });
-    const fitAddon = new FitAddon();
-    terminal.loadAddon(fitAddon);
-    terminal.loadAddon(new WebLinksAddon());
-    terminal.open(document.getElementById("terminal"));
-    fitAddon.fit();
-    terminal.attachCustomKeyEventHandler((event) => {
-      if (event.ctrlKey && event.shiftKey && event.code === "KeyC") {
-        event.preventDefault();
-        const selection = terminal.getSelection();
-        if (selection) {
-          navigator.clipboard.writeText(selection);
-        }
-        return false;
-      }
+  }
 
-      // Ctrl+L для очистки терминала:
-      if (event.ctrlKey && event.code === "KeyL") {
-        event.preventDefault();
-        terminal.clear();
-        return false;
-      }
+  // Adds an ephemeral container, for pods without a shell or debugging tools
+  async debug() {
+    let saved;
+    try {
+      saved = JSON.parse(localStorage.getItem(debugOptionsKey)) || {};
+    } catch {
+      saved = {};
+    }
 
-      return true;
-    });
-    let xtermSize = fitAddon.proposeDimensions();
-    let resizeMessage = {
-      type: "resize",
-      cols: xtermSize.cols,
-      rows: xtermSize.rows,
+    const image = prompt(
+      Utils.translate("Debug image (e.g. nicolaka/netshoot)"),
+      saved.image || "busybox:1.36",
+    );
+    if (image === null) return;
+    const profile = prompt(
+      `${Utils.translate("Profile")} (${debugProfiles.join(", ")})`,
+      saved.profile || "general",
+    );
+    if (profile === null) return;
+    if (!debugProfiles.includes(profile.trim())) {
+      alert(`${Utils.translate("Unknown profile")}: ${profile}`);
+      return;
+    }
+    const target = prompt(
+      Utils.translate("Target container (empty for none)"),
+      this.resource.containers[0] || "",
+    );
+    if (target === null) return;
+
+    const options = {
+      image: image.trim(),
+      profile: profile.trim(),
+      targetContainerName: target.trim(),
     };
+    localStorage.setItem(
+      debugOptionsKey,
+      JSON.stringify({ image: options.image, profile: options.profile }),
+    );
 
-    // WebSocket connection
-    const socket = new WebSocket(
-      `ws://localhost:34116/terminal?` +
-        `cluster=${encodeURIComponent(this.cluster)}&` +
-        `namespace=${encodeURIComponent(this.namespace)}&` +
-        `pod=${encodeURIComponent(this.resource.name)}&` +
-        `container=${encodeURIComponent(containerName)}&` +
-        `command=${encodeURIComponent("/bin/sh")}`,
+    let container;
+    try {
+      Utils.showLoadingIndicator(
+        Utils.translate("Starting debug container"),
+        this.tab,
+      );
+      container = await StartDebugContainer(
+        this.cluster,
+        this.namespace,
+        this.resource.name,
+        options,
+      );
+    } catch (error) {
+      console.error(`Failed to debug ${this.resource.name}:`, error);
+      alert(`${Utils.translate("Debug")} ${this.resource.name}: ${error}`);
+      return;
+    } finally {
+      Utils.hideLoadingIndicator(this.tab);
+    }
+
+    new TerminalWindow(
+      this.tab,
+      Utils.translate("Debug") +
+        ` - ${this.cluster}/${this.namespace}/${this.resource.name}/${container.container}`,
+      {
+        cluster: container.cluster,
+        namespace: container.namespace,
+        pod: container.pod,
+        container: container.container,
+        mode: "attach",
+      },
     );
+  }
 
-    socket.onopen = function () {
-      terminal.focus();
-      socket.send(JSON.stringify(resizeMessage));
-      terminal.write(Utils.translate("connecting") + "...\r\n");
-      console.log("websocket opened");
-    };
+  // Copies files between a container and the local machine, like kubectl cp
+  copyFiles() {
+    const modal = new ModalWindow(
+      this.tab,
+      `<div class="copy-files"></div>`,
+      "modal-content copy-content",
+      Utils.translate("Copy files") +
+        ` - ${this.cluster}/${this.namespace}/${this.resource.name}`,
+    );
+    const formEl = modal.windowEl.querySelector(".copy-files");
 
-    socket.onmessage = function (event) {
-      terminal.write(event.data);
+    const containerEl = Utils.createEl(
+      "search-input selector-input",
+      "",
+      "select",
+    );
+    for (const container of this.resource.containers) {
+      containerEl.append(Utils.createEl("", container, "option"));
+    }
+    const pathEl = Utils.createInputEl(
+      "search-input selector-input",
+      Utils.translate("Path in the container, e.g. /tmp/heap.hprof"),
+    );
+    const statusEl = Utils.createEl("copy-status");
+    const buttonsEl = Utils.createEl("copy-buttons");
+
+    let transfer = null;
+    let stopEvents = null;
+    const setBusy = (busy) => {
+      for (const button of buttonsEl.querySelectorAll("button")) {
+        button.disabled = busy;
+      }
+    };
+    const finish = () => {
+      stopEvents?.();
+      stopEvents = null;
+      transfer = null;
+      setBusy(false);
     };
 
-    socket.onerror = function (event) {
-      console.error("WebSocket error:", event);
+    const run = async (start) => {
+      const path = pathEl.value.trim();
+      if (!path) {
+        pathEl.focus();
+        return;
+      }
+      try {
+        transfer = await start(containerEl.value, path);
+      } catch (error) {
+        statusEl.dataset.type = "error";
+        statusEl.textContent = String(error);
+        return;
+      }
+      // The dialog was cancelled
+      if (!transfer) return;
+
+      setBusy(true);
+      statusEl.dataset.type = "progress";
+      statusEl.textContent = Utils.translate("Copying") + "...";
+      const localPath = transfer.path;
+      stopEvents = EventsOn(transfer.event, (event) => {
+        const size = event.total
+          ? `${Utils.formatBytes(event.bytes)} / ${Utils.formatBytes(event.total)}`
+          : Utils.formatBytes(event.bytes);
+        statusEl.dataset.type = event.type;
+        if (event.type === "progress") {
+          statusEl.textContent = `${size} ${event.file || ""}`;
+          return;
+        }
+        statusEl.textContent =
+          event.type === "done"
+            ? `${Utils.translate("Copied")} ${size}: ${localPath}`
+            : `${Utils.translate("Copy failed")}: ${event.error}`;
+        if (event.skipped?.length) {
+          statusEl.textContent += `\n${Utils.translate("Skipped")}: ${event.skipped.join(", ")}`;
+        }
+        finish();
+      });
     };
 
-    socket.onclose = function () {
-      console.log("websocket closed");
-      terminal.write(Utils.translate("\r\nconnection closed\r\n"));
+    const buttons = {
+      Download: (container, path) =>
+        CopyFromPod(
+          this.cluster,
+          this.namespace,
+          this.resource.name,
+          container,
+          path,
+        ),
+      "Upload file": (container, path) =>
+        CopyToPod(
+          this.cluster,
+          this.namespace,
+          this.resource.name,
+          container,
+          path,
+          false,
+        ),
+      "Upload folder": (container, path) =>
+        CopyToPod(
+          this.cluster,
+          this.namespace,
+          this.resource.name,
+          container,
+          path,
+          true,
+        ),
     };
+    for (const [label, start] of Object.entries(buttons)) {
+      const button = Utils.createEl(
+        "modalButton",
+        Utils.translate(label),
+        "button",
+      );
+      button.addEventListener("click", () => run(start));
+      buttonsEl.append(button);
+    }
 
-    terminal.onData(function (data) {
-      socket.send(data);
-    });
+    formEl.append(containerEl, pathEl, buttonsEl, statusEl);
+    pathEl.focus();
 
-    // Handle window resize
-    window.addEventListener("resize", () => {
-      fitAddon.fit();
-      xtermSize = fitAddon.proposeDimensions();
-      resizeMessage = {
-        type: "resize",
-        cols: xtermSize.cols,
-        rows: xtermSize.rows,
-      };
-      socket.send(JSON.stringify(resizeMessage));
-    });
+    // Closing the modal cancels a running copy
+    const closeModal = modal.close.bind(modal);
+    modal.close = () => {
+      if (transfer) {
+        CancelCopy(transfer.id);
+      }
+      stopEvents?.();
+      closeModal();
+    };
   }
 
   async getEnvoyConfig(container, command) {
@@ -222,4 +397,82 @@ export class PodResource extends Resource {
       console.error("Error viewing resource:", error);
     }
   }
+
+  streamLogs(containerName, tailLines = 1000) {
+    const title =
+      Utils.translate("Live logs") +
+      ` - ${this.cluster}/${this.namespace}/${this.resource.name}/${containerName}`;
+    const modal = new ModalWindow(
+      this.tab,
+      `<div id="terminal"></div>`,
+      "terminal-content",
+      title,
+    );
+    const terminalEl = modal.windowEl.querySelector("#terminal");
+
+    const terminal = new Terminal({
+      convertEol: true,
+      disableStdin: true,
+      scrollback: 100000,
+      fontSize: 16,
+      fontFamily: "Courier New",
+    });
+    const fitAddon = new FitAddon();
+    terminal.loadAddon(fitAddon);
+    terminal.loadAddon(new WebLinksAddon());
+    terminal.open(terminalEl);
+    fitAddon.fit();
+    terminal.attachCustomKeyEventHandler((event) => {
+      if (event.ctrlKey && event.shiftKey && event.code === "KeyC") {
+        event.preventDefault();
+        const selection = terminal.getSelection();
+        if (selection) {
+          navigator.clipboard.writeText(selection);
+        }
+        return false;
+      }
+      return true;
+    });
+
+    const socket = new WebSocket(
+      `ws://localhost:34116/logs?` +
+        `cluster=${encodeURIComponent(this.cluster)}&` +
+        `namespace=${encodeURIComponent(this.namespace)}&` +
+        `pod=${encodeURIComponent(this.resource.name)}&` +
+        `container=${encodeURIComponent(containerName)}&` +
+        `follow=true&tailLines=${tailLines}`,
+    );
+
+    // Closing the modal stops the stream on the backend
+    const closeModal = modal.close.bind(modal);
+    modal.close = () => {
+      socket.close();
+      closeModal();
+    };
+
+    socket.onmessage = (event) => {
+      // The tab holding the modal may have been closed
+      if (!terminalEl.isConnected) {
+        socket.close();
+        return;
+      }
+      terminal.write(event.data);
+    };
+
+    socket.onerror = (event) => {
+      console.error("WebSocket error:", event);
+    };
+
+    socket.onclose = (event) => {
+      if (event.reason) {
+        terminal.write(`\r\n${Utils.translate(event.reason)}\r\n`);
+      }
+    };
+
+    window.addEventListener("resize", () => {
+      if (terminalEl.isConnected) {
+        fitAddon.fit();
+      }
+    });
+  }
 }
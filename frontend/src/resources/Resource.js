@@ -1,9 +1,17 @@
 import {
   GetResourceYAML,
+  GetResourceForEdit,
   DeleteResource,
-  ApplyResource,
+  ApplyResourceWithOptions,
   GetEvents,
+  PreviewApply,
+  StartPortForward,
+  ListPortForwards,
+  StopPortForward,
+  WatchRollout,
+  StopRolloutWatch,
 } from "../../wailsjs/go/main/App.js";
+import { EventsOn } from "../../wailsjs/runtime/runtime.js";
 import { ForwardToOllama } from "../../wailsjs/go/main/OllamaProxy.js";
 
 import { Utils } from "../utils/Utils.js";
@@ -15,6 +23,21 @@ import "@fortawesome/fontawesome-free/css/all.css";
 import { marked } from "marked";
 import DOMPurify from "dompurify";
 
+// Resources of the kinds whose rollout can be tracked
+const ROLLOUT_RESOURCES = {
+  Deployment: "deployments",
+  StatefulSet: "statefulsets",
+  DaemonSet: "daemonsets",
+};
+
+// Joins the diffs of the objects a dry-run would change, with their errors
+function previewDiff(previews) {
+  return previews
+    .filter((p) => p.action !== "unchanged")
+    .map((p) => (p.error ? `# ${p.kind}/${p.name}: ${p.error}\n` : p.diff))
+    .join("\n");
+}
+
 marked.setOptions({
   breaks: true, // Convert \n to <br>
   gfm: true, // GitHub Flavored Markdown
@@ -42,6 +65,8 @@ export class Resource {
     this.actionButtonsEl = Utils.createEl("action-buttons");
     this.editorView = null;
     this.tab = tab;
+    // Set when listed across all namespaces
+    this.showNamespace = false;
   }
 
   async getResourceYAML() {
@@ -63,6 +88,11 @@ export class Resource {
 
   fill() {
     this.htmlEl.setAttribute("data-resource-name", this.resource.name);
+    this.htmlEl.dataset.resourceNamespace = this.resource.namespace ?? "";
+    this.htmlEl.dataset.created =
+      this.resource.metadata?.creationTimestamp ?? "";
+    this.htmlEl.dataset.resourceVersion =
+      this.resource.metadata?.resourceVersion ?? "";
     this.createResourceName();
     this.createOptionalColumns();
     this.createAgeAndActions();
@@ -137,11 +167,13 @@ export class Resource {
         deleteBtn.style.display = anyChecked ? "block" : "none";
       }
     });
-    this.nameEl.append(
-      this.checkBoxEl,
-      this.createResourceText(),
-      this.createCopyButton(),
-    );
+    this.nameEl.append(this.checkBoxEl);
+    if (this.showNamespace) {
+      this.nameEl.append(
+        Utils.createEl("resource-namespace", this.namespace, "span"),
+      );
+    }
+    this.nameEl.append(this.createResourceText(), this.createCopyButton());
   }
 
   createResourceText() {
@@ -155,7 +187,8 @@ export class Resource {
   }
 
   createOptionalColumns() {
-    const columns = RESOURCE_COLUMNS[this.apiResource] || [];
+    const columns =
+      RESOURCE_COLUMNS[Utils.coreApiResource(this.apiResource)] || [];
 
     columns.forEach((column) => {
       let value;
@@ -198,7 +231,7 @@ export class Resource {
     Utils.showLoadingIndicator(Utils.translate("Fetching data"), this.tab);
     try {
       const content = await Promise.resolve(fetchContentCallback());
-      this.setupEditorView(
+      return this.setupEditorView(
         content,
         title,
         button,
@@ -215,7 +248,7 @@ export class Resource {
 
   setupEditorView(content, title, button, buttonHandler, type, editable) {
     const modalContent = `<div class="editor"></div>`;
-    new ModalWindow(
+    const modal = new ModalWindow(
       this.tab,
       modalContent,
       "yaml-content",
@@ -230,12 +263,13 @@ export class Resource {
       // Directly render Markdown as HTML
       container.innerHTML = DOMPurify.sanitize(marked.parse(content));
       container.classList.add("markdown-preview"); // Add styling class
-      return; // Skip editor initialization for markdown
+      return modal; // Skip editor initialization for markdown
     }
     if (this.editorView) {
       this.editorView.dispose();
     }
     this.editorView = Utils.createMonaco(container, content, type, editable);
+    return modal;
   }
 
   async view() {
@@ -248,29 +282,247 @@ export class Resource {
   }
 
   async update() {
+    const updatedContent = this.editorView.getValue(); // Get content from Monaco
+    await this.reviewChanges(updatedContent, this.editOriginal);
+  }
+
+  // Shows the diff of what the server would store before anything is written.
+  // Switching the save mode in the review runs the dry-run again in that mode.
+  async reviewChanges(content, original, serverSide = false) {
+    const previews = await this.previewChanges(content, original, serverSide);
+    if (!previews) {
+      return;
+    }
+    // Someone else changed the object after it was opened
+    const editConflict = previews.find((p) => p.editConflict)?.editConflict;
+    if (editConflict) {
+      this.showEditConflict(content, editConflict, serverSide);
+      return;
+    }
+    if (previews.every((p) => p.action === "unchanged")) {
+      alert(`Resource ${this.resource.name} is unchanged.`);
+      return;
+    }
+
+    let conflicts = previews.flatMap((p) => p.conflicts || []);
+    const modal = this.setupEditorView(
+      previewDiff(previews),
+      Utils.translate("Review changes") +
+        ` - ${this.cluster}/${this.namespace}/${this.apiResource}/${this.resource.name}`,
+      Utils.translate("Apply"),
+      () =>
+        this.applyContent(content, conflicts, original, serverSideEl.checked),
+      "plaintext",
+      false,
+    );
+    const serverSideEl = this.addServerSideToggle(modal);
+    serverSideEl.checked = serverSide;
+    serverSideEl.addEventListener("change", async () => {
+      const updated = await this.previewChanges(
+        content,
+        original,
+        serverSideEl.checked,
+      );
+      if (!updated) {
+        serverSideEl.checked = !serverSideEl.checked;
+        return;
+      }
+      conflicts = updated.flatMap((p) => p.conflicts || []);
+      this.editorView.setValue(previewDiff(updated));
+    });
+  }
+
+  // Dry-runs the content in the given save mode, null when that failed
+  async previewChanges(content, original, serverSide) {
     try {
       Utils.showLoadingIndicator(
         Utils.translate("Updating resource"),
         this.tab,
       );
-      const updatedContent = this.editorView.getValue(); // Get content from Monaco
-      await ApplyResource(this.cluster, updatedContent);
+      return await PreviewApply(this.cluster, content, {
+        serverSide,
+        force: false,
+        original,
+      });
+    } catch (error) {
+      console.error(`Failed to update resource ${this.resource.name}:`, error);
+      alert(`Failed to update resource ${this.resource.name}: ${error}`);
+      return null;
+    } finally {
       Utils.hideLoadingIndicator(this.tab);
-      alert(
-        `Resource ${this.resource.name} of kind ${this.apiResource} updated successfully.`,
+    }
+  }
+
+  async applyContent(content, conflicts, original = null, serverSide = false) {
+    // Fields owned by other managers (HPA, ...) block server-side apply
+    let force = false;
+    if (serverSide && conflicts?.length) {
+      const fields = conflicts
+        .map((c) => `${c.field} (${c.manager || c.message})`)
+        .join("\n");
+      if (
+        !confirm(
+          `These fields are managed by someone else:\n${fields}\n\nForce the update and take ownership of them?`,
+        )
+      ) {
+        return;
+      }
+      force = true;
+    }
+
+    try {
+      Utils.showLoadingIndicator(
+        Utils.translate("Updating resource"),
+        this.tab,
       );
+      const results = await ApplyResourceWithOptions(this.cluster, content, {
+        serverSide,
+        force,
+        original,
+      });
+      Utils.hideLoadingIndicator(this.tab);
+
+      // Someone else changed the object after it was opened
+      const editConflict = results.find((r) => r.editConflict)?.editConflict;
+      if (editConflict) {
+        this.showEditConflict(content, editConflict, serverSide);
+        return;
+      }
+      const summary = results
+        .map(
+          (r) =>
+            `${r.kind}/${r.name} ${r.action}` + (r.error ? `: ${r.error}` : ""),
+        )
+        .join("\n");
+      alert(summary);
+
+      // Changed workloads roll out their pods again
+      for (const result of results) {
+        const apiResource = ROLLOUT_RESOURCES[result.kind];
+        if (
+          apiResource &&
+          (result.action === "created" || result.action === "configured")
+        ) {
+          this.trackRollout(
+            apiResource,
+            result.namespace || this.namespace,
+            result.name,
+          );
+        }
+      }
     } catch (error) {
       console.error(`Failed to update resource ${this.resource.name}:`, error);
-      alert(`Failed to update resource ${this.resource.name}:`, error);
+      alert(`Failed to update resource ${this.resource.name}: ${error}`);
     } finally {
       Utils.hideLoadingIndicator(this.tab);
     }
   }
 
+  // Shows the rollout progress of a Deployment, StatefulSet or DaemonSet until it
+  // completes, fails or times out. Defaults to the rollout of this resource.
+  async trackRollout(
+    apiResource = this.apiResource,
+    namespace = this.namespace,
+    name = this.resource.name,
+  ) {
+    let watch;
+    try {
+      watch = await WatchRollout(this.cluster, namespace, apiResource, name, 0);
+    } catch (error) {
+      console.error(`Failed to track rollout of ${name}:`, error);
+      return;
+    }
+
+    const modal = new ModalWindow(
+      this.tab,
+      `<div class="rollout-status"><div class="rollout-message"></div><div class="rollout-pods"></div></div>`,
+      "modal-content history-content",
+      Utils.translate("Rollout") +
+        ` - ${this.cluster}/${namespace}/${apiResource}/${name}`,
+    );
+    const messageEl = modal.windowEl.querySelector(".rollout-message");
+    const podsEl = modal.windowEl.querySelector(".rollout-pods");
+
+    const showStatus = (status) => {
+      messageEl.textContent = status.ready
+        ? `${status.message} (${status.ready})`
+        : status.message;
+      messageEl.dataset.phase = status.phase;
+
+      podsEl.innerHTML = "";
+      for (const pod of status.failingPods || []) {
+        const podEl = Utils.createEl("rollout-pod");
+        podEl.append(
+          Utils.createEl("rollout-pod-name", pod.name),
+          Utils.createEl("rollout-pod-status", pod.status),
+          Utils.createEl("rollout-pod-restarts", pod.restarts),
+          Utils.createEl("rollout-pod-message", pod.message || ""),
+        );
+        podsEl.append(podEl);
+      }
+    };
+
+    // A rollout that already ended has no events to wait for
+    showStatus(watch.status);
+    if (watch.status.phase !== "progressing") {
+      return;
+    }
+
+    let finished = false;
+    const stopEvents = EventsOn(watch.event, (status) => {
+      showStatus(status);
+      if (status.phase !== "progressing") {
+        finished = true;
+        stopEvents();
+      }
+    });
+
+    // Closing the modal stops tracking on the backend
+    const closeModal = modal.close.bind(modal);
+    modal.close = () => {
+      if (!finished) {
+        stopEvents();
+        StopRolloutWatch(watch.id);
+      }
+      closeModal();
+    };
+  }
+
+  showEditConflict(content, conflict, serverSide = false) {
+    const report =
+      `# ${Utils.translate("Changed on the server since you opened it")}\n` +
+      (conflict.live ? conflict.theirChanges : "# (deleted)\n") +
+      `\n# ${Utils.translate("Your changes")}\n` +
+      conflict.yourChanges;
+    // An overwrite is pinned to the live object, unless that was deleted
+    const live = conflict.live
+      ? { yaml: conflict.live, resourceVersion: conflict.liveResourceVersion }
+      : null;
+    this.setupEditorView(
+      report,
+      Utils.translate("Conflict") +
+        ` - ${this.cluster}/${this.namespace}/${this.apiResource}/${this.resource.name}`,
+      Utils.translate("Overwrite"),
+      // Review the changes again against the object as it is now
+      () => this.reviewChanges(content, live, serverSide),
+      "plaintext",
+      false,
+    );
+  }
+
   async edit() {
     await this.showEditorInModal(
       "yaml",
-      () => this.getResourceYAML(),
+      async () => {
+        // Keep the resourceVersion so that saving detects concurrent changes
+        this.editOriginal = await GetResourceForEdit(
+          this.cluster,
+          this.apiResource,
+          this.namespace,
+          this.resource.name,
+        );
+        return this.editOriginal.yaml;
+      },
       Utils.translate("Edit") +
         ` - ${this.cluster}/${this.namespace}/${this.apiResource}/${this.resource.name}`,
       "Save",
@@ -279,6 +531,18 @@ export class Resource {
     );
   }
 
+  // Saving updates the object like kubectl edit, server-side apply is opt in
+  addServerSideToggle(modal) {
+    const checkboxEl = Utils.createInputEl("server-side-apply", "", "checkbox");
+    const labelEl = Utils.createEl("server-side-apply-label", "", "label");
+    labelEl.title = Utils.translate(
+      "Apply as this app's field manager instead of replacing the object",
+    );
+    labelEl.append(checkboxEl, Utils.translate("Server-side apply"));
+    modal.windowEl.querySelector(".modalButton").before(labelEl);
+    return checkboxEl;
+  }
+
   async delete() {
     if (
       !confirm(
@@ -347,4 +611,121 @@ export class Resource {
       Utils.hideLoadingIndicator(this.tab);
     }
   }
+
+  // Forwards a local port to the pod, or to a ready pod of a service or workload
+  async portForward() {
+    const remoteInput = prompt(
+      `${Utils.translate("Remote port")} (${this.apiResource}/${this.resource.name})`,
+    );
+    if (remoteInput === null) return;
+    const remotePort = Number(remoteInput);
+    if (
+      !Number.isInteger(remotePort) ||
+      remotePort < 1 ||
+      remotePort > 65535
+    ) {
+      alert(Utils.translate("Port must be a number from 1 to 65535"));
+      return;
+    }
+
+    const localInput = prompt(
+      Utils.translate("Local port (0 picks a free port)"),
+      remotePort,
+    );
+    if (localInput === null) return;
+    const localPort = Number(localInput);
+    if (!Number.isInteger(localPort) || localPort < 0 || localPort > 65535) {
+      alert(Utils.translate("Port must be a number from 1 to 65535"));
+      return;
+    }
+
+    try {
+      Utils.showLoadingIndicator(Utils.translate("Port forward"), this.tab);
+      await StartPortForward(
+        this.cluster,
+        this.namespace,
+        this.apiResource,
+        this.resource.name,
+        remotePort,
+        localPort,
+      );
+    } catch (error) {
+      console.error(`Failed to forward port of ${this.resource.name}:`, error);
+      alert(
+        `${Utils.translate("Port forward")} ${this.resource.name}: ${error}`,
+      );
+      return;
+    } finally {
+      Utils.hideLoadingIndicator(this.tab);
+    }
+    this.showPortForwards();
+  }
+
+  // Lists the running port forwards with their traffic, refreshed every second
+  showPortForwards() {
+    const modal = new ModalWindow(
+      this.tab,
+      `<div class="port-forwards"></div>`,
+      "modal-content history-content",
+      Utils.translate("Port forwards"),
+    );
+    const listEl = modal.windowEl.querySelector(".port-forwards");
+
+    const refresh = async () => {
+      const sessions = await ListPortForwards();
+      listEl.innerHTML = "";
+      if (!sessions?.length) {
+        listEl.textContent = Utils.translate("No port forwards");
+        return;
+      }
+      for (const session of sessions) {
+        const rowEl = Utils.createEl("port-forward");
+        const statusEl = Utils.createEl(
+          "port-forward-status",
+          Utils.translate(session.status),
+        );
+        statusEl.dataset.status = session.status;
+        if (session.error) statusEl.title = session.error;
+
+        const stopButton = Utils.createEl(
+          "modalButton",
+          Utils.translate("Stop"),
+          "button",
+        );
+        stopButton.addEventListener("click", async () => {
+          await StopPortForward(session.id);
+          refresh();
+        });
+
+        rowEl.append(
+          Utils.createEl(
+            "port-forward-address",
+            `127.0.0.1:${session.localPort} → ${session.remotePort}`,
+          ),
+          Utils.createEl(
+            "port-forward-target",
+            `${session.cluster}/${session.namespace}/${session.resource}/${session.name}` +
+              (session.pod && session.pod !== session.name
+                ? ` (${session.pod})`
+                : ""),
+          ),
+          statusEl,
+          Utils.createEl(
+            "port-forward-traffic",
+            `↓ ${Utils.formatBytes(session.bytesIn)} ↑ ${Utils.formatBytes(session.bytesOut)}`,
+          ),
+          stopButton,
+        );
+        listEl.append(rowEl);
+      }
+    };
+
+    refresh();
+    const interval = setInterval(refresh, 1000);
+    const closeModal = modal.close.bind(modal);
+    modal.close = () => {
+      clearInterval(interval);
+      closeModal();
+    };
+  }
 }
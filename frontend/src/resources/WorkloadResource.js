@@ -0,0 +1,317 @@
+import {
+  GetScale,
+  ScaleResource,
+  RestartWorkload,
+  PauseRollout,
+  ResumeRollout,
+  GetRolloutHistory,
+  RollbackWorkload,
+} from "../../wailsjs/go/main/App.js";
+import { Resource } from "./Resource";
+import { ModalWindow } from "../windows/ModalWindow.js";
+import { Utils } from "../utils/Utils.js";
+
+import "@fortawesome/fontawesome-free/css/all.css";
+import { Terminal } from "@xterm/xterm";
+import { WebLinksAddon } from "@xterm/addon-web-links";
+import { FitAddon } from "@xterm/addon-fit";
+import "@xterm/xterm/css/xterm.css";
+
+// ANSI colors used to tell pods apart in merged logs
+const podColors = [31, 32, 33, 34, 35, 36, 91, 92, 93, 94, 95, 96];
+
+const scalable = ["deployments", "statefulsets", "replicasets"];
+const rollouts = ["deployments", "statefulsets", "daemonsets"];
+const forwardable = [...rollouts, "replicasets", "services"];
+
+export class WorkloadResource extends Resource {
+  constructor(tab, cluster, namespace, apiResource, resource) {
+    super(tab, cluster, namespace, apiResource, resource);
+    this.extraActions = {
+      Logs: () => this.streamLogs(),
+    };
+    if (scalable.includes(apiResource)) {
+      this.extraActions.Scale = () => this.scale();
+    }
+    if (rollouts.includes(apiResource)) {
+      this.extraActions.Restart = () => this.restart();
+      this.extraActions.History = () => this.showHistory();
+    }
+    if (forwardable.includes(apiResource)) {
+      this.extraActions["Port forward"] = () => this.portForward();
+    }
+    if (apiResource === "deployments") {
+      if (resource.spec?.paused) {
+        this.extraActions.Resume = () => this.setPaused(false);
+      } else {
+        this.extraActions.Pause = () => this.setPaused(true);
+      }
+    }
+  }
+
+  // Runs a workload action with the loading indicator, alerting on failure
+  async runAction(message, action) {
+    try {
+      Utils.showLoadingIndicator(Utils.translate(message), this.tab);
+      await action();
+      return true;
+    } catch (error) {
+      console.error(`${message} ${this.resource.name} failed:`, error);
+      alert(`${Utils.translate(message)} ${this.resource.name}: ${error}`);
+      return false;
+    } finally {
+      Utils.hideLoadingIndicator(this.tab);
+    }
+  }
+
+  async scale() {
+    let current;
+    try {
+      current = await GetScale(
+        this.cluster,
+        this.namespace,
+        this.apiResource,
+        this.resource.name,
+      );
+    } catch (error) {
+      alert(`${Utils.translate("Scale")} ${this.resource.name}: ${error}`);
+      return;
+    }
+
+    const input = prompt(
+      `${Utils.translate("Replicas")} (${this.apiResource}/${this.resource.name})`,
+      current.replicas,
+    );
+    if (input === null) return;
+    const replicas = Number(input);
+    if (!Number.isInteger(replicas) || replicas < 0) {
+      alert(Utils.translate("Replicas must be a non-negative number"));
+      return;
+    }
+
+    const done = await this.runAction("Scaling", () =>
+      ScaleResource(
+        this.cluster,
+        this.namespace,
+        this.apiResource,
+        this.resource.name,
+        replicas,
+      ),
+    );
+    if (done && rollouts.includes(this.apiResource)) {
+      this.trackRollout();
+    }
+  }
+
+  async restart() {
+    if (
+      !confirm(
+        `Are you sure you want to restart ${this.apiResource} "${this.resource.name}" in namespace "${this.namespace}"?`,
+      )
+    ) {
+      return;
+    }
+    const done = await this.runAction("Restarting", () =>
+      RestartWorkload(
+        this.cluster,
+        this.namespace,
+        this.apiResource,
+        this.resource.name,
+      ),
+    );
+    if (done) {
+      this.trackRollout();
+    }
+  }
+
+  async setPaused(paused) {
+    await this.runAction(paused ? "Pausing" : "Resuming", () =>
+      (paused ? PauseRollout : ResumeRollout)(
+        this.cluster,
+        this.namespace,
+        this.resource.name,
+      ),
+    );
+  }
+
+  async showHistory() {
+    let revisions;
+    try {
+      revisions = await GetRolloutHistory(
+        this.cluster,
+        this.namespace,
+        this.apiResource,
+        this.resource.name,
+      );
+    } catch (error) {
+      alert(`${Utils.translate("History")} ${this.resource.name}: ${error}`);
+      return;
+    }
+
+    const modal = new ModalWindow(
+      this.tab,
+      `<div class="rollout-history"></div>`,
+      "modal-content history-content",
+      Utils.translate("History") +
+        ` - ${this.cluster}/${this.namespace}/${this.apiResource}/${this.resource.name}`,
+    );
+    const historyEl = modal.windowEl.querySelector(".rollout-history");
+
+    if (!revisions?.length) {
+      historyEl.textContent = Utils.translate("No revisions found");
+      return;
+    }
+
+    // Newest revision first
+    for (const revision of [...revisions].reverse()) {
+      const rowEl = Utils.createEl("rollout-revision");
+      rowEl.append(
+        Utils.createEl("revision-number", `#${revision.revision}`),
+        Utils.createEl("revision-name", revision.name),
+        Utils.createEl("revision-images", (revision.images || []).join(", ")),
+        Utils.createEl("revision-cause", revision.changeCause || ""),
+        Utils.createEl("revision-age", revision.age),
+      );
+
+      if (revision.current) {
+        rowEl.append(
+          Utils.createEl("revision-current", Utils.translate("current")),
+        );
+      } else {
+        const button = Utils.createEl(
+          "modalButton",
+          Utils.translate("Rollback"),
+          "button",
+        );
+        button.addEventListener("click", async () => {
+          if (
+            !confirm(
+              `Are you sure you want to roll back ${this.apiResource} "${this.resource.name}" to revision ${revision.revision}?`,
+            )
+          ) {
+            return;
+          }
+          const done = await this.runAction("Rolling back", () =>
+            RollbackWorkload(
+              this.cluster,
+              this.namespace,
+              this.apiResource,
+              this.resource.name,
+              revision.revision,
+            ),
+          );
+          if (done) {
+            modal.close();
+            this.trackRollout();
+          }
+        });
+        rowEl.append(button);
+      }
+      historyEl.append(rowEl);
+    }
+  }
+
+  podColor(pod) {
+    let hash = 0;
+    for (const char of pod) {
+      hash = (hash * 31 + char.charCodeAt(0)) | 0;
+    }
+    return podColors[Math.abs(hash) % podColors.length];
+  }
+
+  formatLine(line) {
+    const prefix = `\x1b[${this.podColor(line.pod)}m${line.pod}\x1b[0m \x1b[2m${line.container}\x1b[0m`;
+    if (line.event === "started") {
+      return `\x1b[32m+\x1b[0m ${prefix}\r\n`;
+    }
+    if (line.event === "ended") {
+      return `\x1b[31m-\x1b[0m ${prefix}\r\n`;
+    }
+    // Too many containers are streamed, this one starts when another ends
+    if (line.event === "queued") {
+      return `\x1b[33m~\x1b[0m ${prefix} ${Utils.translate("queued")}\r\n`;
+    }
+    return `${prefix} ${line.message}\r\n`;
+  }
+
+  streamLogs(tailLines = 100) {
+    const title =
+      Utils.translate("Logs") +
+      ` - ${this.cluster}/${this.namespace}/${this.apiResource}/${this.resource.name}`;
+    const modal = new ModalWindow(
+      this.tab,
+      `<div id="terminal"></div>`,
+      "terminal-content",
+      title,
+    );
+    const terminalEl = modal.windowEl.querySelector("#terminal");
+
+    const terminal = new Terminal({
+      disableStdin: true,
+      scrollback: 100000,
+      fontSize: 16,
+      fontFamily: "Courier New",
+    });
+    const fitAddon = new FitAddon();
+    terminal.loadAddon(fitAddon);
+    terminal.loadAddon(new WebLinksAddon());
+    terminal.open(terminalEl);
+    fitAddon.fit();
+    terminal.attachCustomKeyEventHandler((event) => {
+      if (event.ctrlKey && event.shiftKey && event.code === "KeyC") {
+        event.preventDefault();
+        const selection = terminal.getSelection();
+        if (selection) {
+          navigator.clipboard.writeText(selection);
+        }
+        return false;
+      }
+      return true;
+    });
+
+    const socket = new WebSocket(
+      `ws://localhost:34116/workload-logs?` +
+        `cluster=${encodeURIComponent(this.cluster)}&` +
+        `namespace=${encodeURIComponent(this.namespace)}&` +
+        `resource=${encodeURIComponent(this.apiResource)}&` +
+        `name=${encodeURIComponent(this.resource.name)}&` +
+        `tailLines=${tailLines}`,
+    );
+
+    // Closing the modal stops all pod streams on the backend
+    const closeModal = modal.close.bind(modal);
+    modal.close = () => {
+      socket.close();
+      closeModal();
+    };
+
+    socket.onmessage = (event) => {
+      // The tab holding the modal may have been closed
+      if (!terminalEl.isConnected) {
+        socket.close();
+        return;
+      }
+      terminal.write(
+        JSON.parse(event.data)
+          .map((line) => this.formatLine(line))
+          .join(""),
+      );
+    };
+
+    socket.onerror = (event) => {
+      console.error("WebSocket error:", event);
+    };
+
+    socket.onclose = (event) => {
+      if (event.reason) {
+        terminal.write(`\r\n${Utils.translate(event.reason)}\r\n`);
+      }
+    };
+
+    window.addEventListener("resize", () => {
+      if (terminalEl.isConnected) {
+        fitAddon.fit();
+      }
+    });
+  }
+}
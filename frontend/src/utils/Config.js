@@ -1,6 +1,9 @@
 class Config {
   static lang = "en";
 }
+
+// Namespace value that lists namespaced resources of all namespaces, like kubectl get -A
+const ALL_NAMESPACES = "*";
 const defaultApiResourcesGroups = {
   groups: {
     Workloads: [
@@ -48,7 +51,7 @@ const defaultApiResourcesGroups = {
     Cluster: [
       "namespaces",
       "nodes",
-      "events",
+      "events.v1",
       "customresourcedefinitions",
       "runtimeclasses",
       "priorityclasses",
@@ -77,6 +80,10 @@ const translations = {
     Uncategorized: "Без группы",
     Select: "Выбор",
     Logs: "Логи",
+    "Live logs": "Логи в реальном времени",
+    "All namespaces": "Все пространства имён",
+    "all namespaces": "всех пространствах имён",
+    "end of log": "конец лога",
     Terminal: "Терминал",
     Copied: "Скопировано",
     resource: "русурс",
@@ -121,11 +128,79 @@ const translations = {
     "Error loading namespaces": "Ошибка загрузки пространств",
     "Error loading resource types": "Ошибка загрузки типов ресурсов",
     "Error fetching resources": "Ошибка получения ресурсов",
+    "Server-side apply": "Серверное применение",
+    "Apply as this app's field manager instead of replacing the object":
+      "Применить как менеджер полей приложения вместо замены объекта",
     "Are you sure you want to delete": "Вы уверены, что хотите удалить",
     "Opening terminal for pod": "Открытие терминала для пода",
     "Viewing logs for pod": "Просмотр логов для пода",
     "Loading cluster resources": "Загрузка ресурсов кластера",
     "No items found for search query": "Ничего не найдено по запросу",
+    "Label selector": "Селектор меток",
+    "Field selector": "Селектор полей",
+    "Error at position": "Ошибка в позиции",
+    Scale: "Масштабировать",
+    Restart: "Перезапустить",
+    History: "История",
+    Pause: "Приостановить",
+    Resume: "Возобновить",
+    Rollback: "Откатить",
+    Replicas: "Реплики",
+    "Replicas must be a non-negative number":
+      "Число реплик должно быть неотрицательным",
+    Scaling: "Масштабирование",
+    Restarting: "Перезапуск",
+    Pausing: "Приостановка",
+    Resuming: "Возобновление",
+    "Rolling back": "Откат",
+    "No revisions found": "Ревизии не найдены",
+    current: "текущая",
+    Rollout: "Развёртывание",
+    queued: "в очереди",
+    "Port forward": "Проброс порта",
+    "Port forwards": "Проброшенные порты",
+    "Remote port": "Удалённый порт",
+    "Local port (0 picks a free port)":
+      "Локальный порт (0 - выбрать свободный)",
+    "Port must be a number from 1 to 65535":
+      "Порт должен быть числом от 1 до 65535",
+    "No port forwards": "Нет проброшенных портов",
+    Stop: "Остановить",
+    connecting: "подключение",
+    active: "активен",
+    reconnecting: "переподключение",
+    failed: "завершён с ошибкой",
+    Shell: "Оболочка",
+    "Shell settings": "Настройки оболочки",
+    "Starting node shell": "Запуск оболочки узла",
+    "Node shell image (empty for busybox)":
+      "Образ оболочки узла (пусто - busybox)",
+    "Node shell namespace": "Пространство для пода оболочки",
+    "Tolerations as JSON (empty tolerates all taints)":
+      "Tolerations в JSON (пусто - допускать все taints)",
+    "Invalid JSON": "Некорректный JSON",
+    Debug: "Отладка",
+    "Debug image (e.g. nicolaka/netshoot)":
+      "Образ для отладки (например, nicolaka/netshoot)",
+    Profile: "Профиль",
+    "Unknown profile": "Неизвестный профиль",
+    "Target container (empty for none)": "Целевой контейнер (пусто - без него)",
+    "Starting debug container": "Запуск отладочного контейнера",
+    Attach: "Подключиться",
+    "If you don't see a command prompt, try pressing enter.":
+      "Если приглашение командной строки не появилось, нажмите Enter.",
+    "Copy files": "Копировать файлы",
+    "Path in the container, e.g. /tmp/heap.hprof":
+      "Путь в контейнере, например /tmp/heap.hprof",
+    Download: "Скачать",
+    "Upload file": "Загрузить файл",
+    "Upload folder": "Загрузить папку",
+    Copying: "Копирование",
+    Copied: "Скопировано",
+    "Copy failed": "Ошибка копирования",
+    Skipped: "Пропущено",
+    "Tolerations must be a JSON array":
+      "Tolerations должны быть JSON-массивом",
   },
 };
 
@@ -136,6 +211,18 @@ const icons = {
   Delete: "fa-trash",
   Terminal: "fa-terminal",
   Logs: "fa-file-lines",
+  "Live logs": "fa-scroll",
+  Scale: "fa-up-down",
+  Restart: "fa-rotate-right",
+  History: "fa-clock-rotate-left",
+  Pause: "fa-pause",
+  Resume: "fa-play",
+  "Port forward": "fa-right-left",
+  Shell: "fa-server",
+  Debug: "fa-bug",
+  Attach: "fa-plug",
+  "Copy files": "fa-file-export",
+  "Shell settings": "fa-gear",
   Events: "fa-triangle-exclamation",
   Decode: "fa-unlock",
   "Istio config": "fa-circle-nodes",
@@ -146,7 +233,7 @@ const RESOURCE_COLUMNS = {
   pods: [
     { key: "readyStatus", title: "Ready" },
     { key: "status", title: "Status" },
-    { key: "restarts", title: "Restarts" }
+    { key: "restartsDisplay", title: "Restarts" }
   ],
   deployments: [
     { key: "ready", title: "Ready" },
@@ -156,8 +243,16 @@ const RESOURCE_COLUMNS = {
   services: [
     { key: "type", title: "Type" },
     { key: "clusterIP", title: "ClusterIP" },
-    { key: "loadBalancerIP", title: "LoadBalancerIP" }
+    { key: "externalIP", title: "ExternalIP" },
+    { key: "ports", title: "Ports" }
   ]
 };
 
-export { Config, translations, defaultApiResourcesGroups, icons, RESOURCE_COLUMNS };
+export {
+  Config,
+  translations,
+  defaultApiResourcesGroups,
+  icons,
+  RESOURCE_COLUMNS,
+  ALL_NAMESPACES,
+};
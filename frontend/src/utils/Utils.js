@@ -62,6 +62,12 @@ export class Utils {
     return input;
   }
 
+  // Core resources also served by another API group are listed as
+  // "services.v1", columns and resource classes are keyed by the bare name
+  static coreApiResource(apiResource) {
+    return apiResource.replace(/\.v1$/, "");
+  }
+
   // Create an error message element
   static createErrorMessage(message) {
     return `<div class="error-message" role="alert">
@@ -70,6 +76,30 @@ export class Utils {
             </div>`;
   }
 
+  // Format the time since an RFC3339 timestamp like the backend's formatAge
+  static formatAge(timestamp) {
+    const seconds = Math.floor((Date.now() - Date.parse(timestamp)) / 1000);
+    if (Number.isNaN(seconds)) return "N/A";
+    if (seconds >= 86400) return `${Math.floor(seconds / 86400)}d`;
+    if (seconds >= 3600) return `${Math.floor(seconds / 3600)}h`;
+    if (seconds >= 60) return `${Math.floor(seconds / 60)}m`;
+    return `${seconds}s`;
+  }
+
+  // Format a byte count with a binary unit, e.g. 1.5 KiB
+  static formatBytes(bytes) {
+    const units = ["B", "KiB", "MiB", "GiB", "TiB"];
+    let value = bytes;
+    let unit = 0;
+    while (value >= 1024 && unit < units.length - 1) {
+      value /= 1024;
+      unit++;
+    }
+    return unit === 0
+      ? `${value} ${units[0]}`
+      : `${value.toFixed(1)} ${units[unit]}`;
+  }
+
   // Translate a key based on the current language
   static translate(key) {
     return translations[Config.lang]?.[key] || key;
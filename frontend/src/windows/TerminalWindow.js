@@ -0,0 +1,106 @@
+import { ModalWindow } from "./ModalWindow";
+import { Utils } from "../utils/Utils";
+
+import { Terminal } from "@xterm/xterm";
+import { WebLinksAddon } from "@xterm/addon-web-links";
+import { FitAddon } from "@xterm/addon-fit";
+import "@xterm/xterm/css/xterm.css";
+
+// Interactive terminal connected to the /terminal WebSocket. params are its query
+// parameters: cluster, namespace, pod, container, and command, or mode "attach"
+// to connect to the container's running process.
+export class TerminalWindow extends ModalWindow {
+  constructor(tab, title, params) {
+    super(tab, `<div id="terminal"></div>`, "terminal-content", title);
+
+    this.terminal = new Terminal({
+      cursorBlink: true,
+      fontSize: 20,
+      fontFamily: "Courier New",
+    });
+    this.fitAddon = new FitAddon();
+    this.terminal.loadAddon(this.fitAddon);
+    this.terminal.loadAddon(new WebLinksAddon());
+    this.terminal.open(this.windowEl.querySelector("#terminal"));
+    this.fitAddon.fit();
+    this.terminal.attachCustomKeyEventHandler((event) => {
+      if (event.ctrlKey && event.shiftKey && event.code === "KeyC") {
+        event.preventDefault();
+        const selection = this.terminal.getSelection();
+        if (selection) {
+          navigator.clipboard.writeText(selection);
+        }
+        return false;
+      }
+
+      // Ctrl+L для очистки терминала:
+      if (event.ctrlKey && event.code === "KeyL") {
+        event.preventDefault();
+        this.terminal.clear();
+        return false;
+      }
+
+      return true;
+    });
+
+    const query = Object.entries(params)
+      .map(([key, value]) => `${key}=${encodeURIComponent(value)}`)
+      .join("&");
+    this.socket = new WebSocket(`ws://localhost:34116/terminal?${query}`);
+
+    this.socket.onopen = () => {
+      this.terminal.focus();
+      this.sendSize();
+      this.terminal.write(Utils.translate("connecting") + "...\r\n");
+      if (params.mode === "attach") {
+        // An attached shell prints its prompt only after the next input
+        this.terminal.write(
+          Utils.translate(
+            "If you don't see a command prompt, try pressing enter.",
+          ) + "\r\n",
+        );
+      }
+    };
+    this.socket.onmessage = (event) => {
+      this.terminal.write(event.data);
+    };
+    this.socket.onerror = (event) => {
+      console.error("WebSocket error:", event);
+    };
+    this.socket.onclose = (event) => {
+      if (event.reason) {
+        this.terminal.write(`\r\n${event.reason}`);
+      }
+      this.terminal.write(Utils.translate("\r\nconnection closed\r\n"));
+    };
+
+    this.terminal.onData((data) => {
+      if (this.socket.readyState === WebSocket.OPEN) {
+        this.socket.send(data);
+      }
+    });
+
+    this.onResize = () => {
+      this.fitAddon.fit();
+      this.sendSize();
+    };
+    window.addEventListener("resize", this.onResize);
+  }
+
+  sendSize() {
+    const size = this.fitAddon.proposeDimensions();
+    if (size && this.socket.readyState === WebSocket.OPEN) {
+      this.socket.send(
+        JSON.stringify({ type: "resize", cols: size.cols, rows: size.rows }),
+      );
+    }
+  }
+
+  // Closing the window ends the session
+  close() {
+    window.removeEventListener("resize", this.onResize);
+    this.socket.close();
+    this.terminal.dispose();
+    super.close();
+  }
+}
@@ -5,8 +5,20 @@ import {api} from '../models';
 
 export function ApplyResource(arg1:string,arg2:string):Promise<void>;
 
+export function ApplyResourceWithOptions(arg1:string,arg2:string,arg3:main.ApplyOptions):Promise<Array<main.ApplyResult>>;
+
+export function CancelCopy(arg1:string):Promise<void>;
+
+export function CancelSearch(arg1:string):Promise<void>;
+
+export function CopyFromPod(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<main.CopyTransfer>;
+
+export function CopyToPod(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:boolean):Promise<main.CopyTransfer>;
+
 export function DeleteResource(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function DisconnectCluster(arg1:string):Promise<void>;
+
 export function GetApiResources(arg1:string):Promise<main.APIResourceMap>;
 
 export function GetClusters():Promise<{[key: string]: api.Context}>;
@@ -21,10 +33,60 @@ export function GetPodContainerLogs(arg1:string,arg2:string,arg3:string,arg4:str
 
 export function GetResourceDependencies(arg1:string,arg2:string,arg3:string,arg4:string):Promise<main.DependencyChain>;
 
+export function GetResourceForEdit(arg1:string,arg2:string,arg3:string,arg4:string):Promise<main.EditableResource>;
+
+export function GetResourceTable(arg1:string,arg2:string,arg3:string,arg4:main.PageOptions):Promise<main.ResourceTable>;
+
+export function GetResourceTableRow(arg1:string,arg2:string,arg3:string,arg4:string):Promise<main.ResourceTable>;
+
 export function GetResourceYAML(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
 
-export function GetResourcesInNamespace(arg1:string,arg2:string,arg3:string):Promise<Array<any>>;
+export function GetResourcesInNamespace(arg1:string,arg2:string,arg3:string,arg4:main.ResourceSelectors):Promise<Array<any>>;
+
+export function GetRolloutHistory(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Array<main.RolloutRevision>>;
+
+export function GetScale(arg1:string,arg2:string,arg3:string,arg4:string):Promise<main.WorkloadScale>;
+
+export function ListPortForwards():Promise<Array<main.PortForwardSession>>;
+
+export function ListResourcesPage(arg1:string,arg2:string,arg3:string,arg4:main.PageOptions):Promise<main.ResourcePage>;
+
+export function PauseRollout(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function PreviewApply(arg1:string,arg2:string,arg3:main.ApplyOptions):Promise<Array<main.ApplyPreview>>;
+
+export function RefreshApiResources(arg1:string):Promise<void>;
+
+export function RestartWorkload(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function ResumeRollout(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function RollbackWorkload(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<void>;
+
+export function ScaleResource(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<void>;
+
+export function SearchClusters(arg1:main.ClusterSearchRequest):Promise<main.ClusterSearch>;
+
+export function StartDebugContainer(arg1:string,arg2:string,arg3:string,arg4:main.DebugContainerOptions):Promise<main.DebugContainer>;
+
+export function StartNodeShell(arg1:string,arg2:string,arg3:main.NodeShellOptions):Promise<main.NodeShell>;
+
+export function StartPortForward(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:number):Promise<main.PortForwardSession>;
 
 export function StartWebSocketServer():Promise<void>;
 
+export function StopNodeShell(arg1:string):Promise<void>;
+
+export function StopPortForward(arg1:string):Promise<void>;
+
+export function StopRolloutWatch(arg1:string):Promise<void>;
+
+export function SubscribeResources(arg1:string,arg2:string,arg3:string,arg4:main.ResourceSelectors):Promise<main.ResourceSubscription>;
+
 export function TestClusterConnectivity(arg1:string):Promise<boolean>;
+
+export function UnsubscribeResources(arg1:string):Promise<void>;
+
+export function ValidateSelectors(arg1:main.ResourceSelectors):Promise<Array<main.SelectorError>>;
+
+export function WatchRollout(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<main.RolloutWatch>;
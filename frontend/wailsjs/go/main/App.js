@@ -6,10 +6,34 @@ export function ApplyResource(arg1, arg2) {
   return window['go']['main']['App']['ApplyResource'](arg1, arg2);
 }
 
+export function ApplyResourceWithOptions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ApplyResourceWithOptions'](arg1, arg2, arg3);
+}
+
+export function CancelCopy(arg1) {
+  return window['go']['main']['App']['CancelCopy'](arg1);
+}
+
+export function CancelSearch(arg1) {
+  return window['go']['main']['App']['CancelSearch'](arg1);
+}
+
+export function CopyFromPod(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['CopyFromPod'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function CopyToPod(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['CopyToPod'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function DeleteResource(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['DeleteResource'](arg1, arg2, arg3, arg4);
 }
 
+export function DisconnectCluster(arg1) {
+  return window['go']['main']['App']['DisconnectCluster'](arg1);
+}
+
 export function GetApiResources(arg1) {
   return window['go']['main']['App']['GetApiResources'](arg1);
 }
@@ -38,18 +62,118 @@ export function GetResourceDependencies(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['GetResourceDependencies'](arg1, arg2, arg3, arg4);
 }
 
+export function GetResourceForEdit(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetResourceForEdit'](arg1, arg2, arg3, arg4);
+}
+
+export function GetResourceTable(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetResourceTable'](arg1, arg2, arg3, arg4);
+}
+
+export function GetResourceTableRow(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetResourceTableRow'](arg1, arg2, arg3, arg4);
+}
+
 export function GetResourceYAML(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['GetResourceYAML'](arg1, arg2, arg3, arg4);
 }
 
-export function GetResourcesInNamespace(arg1, arg2, arg3) {
-  return window['go']['main']['App']['GetResourcesInNamespace'](arg1, arg2, arg3);
+export function GetResourcesInNamespace(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetResourcesInNamespace'](arg1, arg2, arg3, arg4);
+}
+
+export function GetRolloutHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetRolloutHistory'](arg1, arg2, arg3, arg4);
+}
+
+export function GetScale(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetScale'](arg1, arg2, arg3, arg4);
+}
+
+export function ListPortForwards() {
+  return window['go']['main']['App']['ListPortForwards']();
+}
+
+export function ListResourcesPage(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ListResourcesPage'](arg1, arg2, arg3, arg4);
+}
+
+export function PauseRollout(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PauseRollout'](arg1, arg2, arg3);
+}
+
+export function PreviewApply(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewApply'](arg1, arg2, arg3);
+}
+
+export function RefreshApiResources(arg1) {
+  return window['go']['main']['App']['RefreshApiResources'](arg1);
+}
+
+export function RestartWorkload(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RestartWorkload'](arg1, arg2, arg3, arg4);
+}
+
+export function ResumeRollout(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ResumeRollout'](arg1, arg2, arg3);
+}
+
+export function RollbackWorkload(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['RollbackWorkload'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ScaleResource(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ScaleResource'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function SearchClusters(arg1) {
+  return window['go']['main']['App']['SearchClusters'](arg1);
+}
+
+export function StartDebugContainer(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['StartDebugContainer'](arg1, arg2, arg3, arg4);
+}
+
+export function StartNodeShell(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartNodeShell'](arg1, arg2, arg3);
+}
+
+export function StartPortForward(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['StartPortForward'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
 export function StartWebSocketServer() {
   return window['go']['main']['App']['StartWebSocketServer']();
 }
 
+export function StopNodeShell(arg1) {
+  return window['go']['main']['App']['StopNodeShell'](arg1);
+}
+
+export function StopPortForward(arg1) {
+  return window['go']['main']['App']['StopPortForward'](arg1);
+}
+
+export function StopRolloutWatch(arg1) {
+  return window['go']['main']['App']['StopRolloutWatch'](arg1);
+}
+
+export function SubscribeResources(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SubscribeResources'](arg1, arg2, arg3, arg4);
+}
+
 export function TestClusterConnectivity(arg1) {
   return window['go']['main']['App']['TestClusterConnectivity'](arg1);
 }
+
+export function UnsubscribeResources(arg1) {
+  return window['go']['main']['App']['UnsubscribeResources'](arg1);
+}
+
+export function ValidateSelectors(arg1) {
+  return window['go']['main']['App']['ValidateSelectors'](arg1);
+}
+
+export function WatchRollout(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['WatchRollout'](arg1, arg2, arg3, arg4, arg5);
+}
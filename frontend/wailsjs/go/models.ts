@@ -16,6 +16,266 @@ export namespace main {
 	        this.cluster = source["cluster"];
 	    }
 	}
+	export class EditableResource {
+	    yaml: string;
+	    resourceVersion: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EditableResource(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.yaml = source["yaml"];
+	        this.resourceVersion = source["resourceVersion"];
+	    }
+	}
+	export class ApplyOptions {
+	    serverSide: boolean;
+	    force: boolean;
+	    original?: EditableResource;
+	
+	    static createFrom(source: any = {}) {
+	        return new ApplyOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.serverSide = source["serverSide"];
+	        this.force = source["force"];
+	        this.original = this.convertValues(source["original"], EditableResource);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EditConflictError {
+	    original: string;
+	    edited: string;
+	    live: string;
+	    liveResourceVersion?: string;
+	    theirChanges: string;
+	    yourChanges: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EditConflictError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.original = source["original"];
+	        this.edited = source["edited"];
+	        this.live = source["live"];
+	        this.liveResourceVersion = source["liveResourceVersion"];
+	        this.theirChanges = source["theirChanges"];
+	        this.yourChanges = source["yourChanges"];
+	    }
+	}
+	export class FieldConflict {
+	    manager: string;
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.manager = source["manager"];
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ApplyPreview {
+	    kind: string;
+	    name: string;
+	    namespace?: string;
+	    action: string;
+	    conflicts?: FieldConflict[];
+	    editConflict?: EditConflictError;
+	    error?: string;
+	    diff: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ApplyPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.namespace = source["namespace"];
+	        this.action = source["action"];
+	        this.conflicts = this.convertValues(source["conflicts"], FieldConflict);
+	        this.editConflict = this.convertValues(source["editConflict"], EditConflictError);
+	        this.error = source["error"];
+	        this.diff = source["diff"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ApplyResult {
+	    kind: string;
+	    name: string;
+	    namespace?: string;
+	    action: string;
+	    conflicts?: FieldConflict[];
+	    editConflict?: EditConflictError;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ApplyResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.namespace = source["namespace"];
+	        this.action = source["action"];
+	        this.conflicts = this.convertValues(source["conflicts"], FieldConflict);
+	        this.editConflict = this.convertValues(source["editConflict"], EditConflictError);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ClusterSearch {
+	    id: string;
+	    event: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClusterSearch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.event = source["event"];
+	    }
+	}
+	export class ClusterSearchRequest {
+	    clusters: string[];
+	    resources: string[];
+	    namespace?: string;
+	    name?: string;
+	    labelSelector?: string;
+	    image?: string;
+	    timeoutSeconds?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClusterSearchRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.clusters = source["clusters"];
+	        this.resources = source["resources"];
+	        this.namespace = source["namespace"];
+	        this.name = source["name"];
+	        this.labelSelector = source["labelSelector"];
+	        this.image = source["image"];
+	        this.timeoutSeconds = source["timeoutSeconds"];
+	    }
+	}
+	export class CopyTransfer {
+	    id: string;
+	    event: string;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CopyTransfer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.event = source["event"];
+	        this.path = source["path"];
+	    }
+	}
+	export class DebugContainer {
+	    cluster: string;
+	    namespace: string;
+	    pod: string;
+	    container: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugContainer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.cluster = source["cluster"];
+	        this.namespace = source["namespace"];
+	        this.pod = source["pod"];
+	        this.container = source["container"];
+	    }
+	}
+	export class DebugContainerOptions {
+	    image?: string;
+	    targetContainerName?: string;
+	    profile?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugContainerOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.image = source["image"];
+	        this.targetContainerName = source["targetContainerName"];
+	        this.profile = source["profile"];
+	    }
+	}
 	export class ResourceRef {
 	    name: string;
 	    kind: string;
@@ -70,6 +330,419 @@ export namespace main {
 		    return a;
 		}
 	}
+	
+	
+	export class FailingPod {
+	    name: string;
+	    status: string;
+	    restarts: string;
+	    message?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FailingPod(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.restarts = source["restarts"];
+	        this.message = source["message"];
+	    }
+	}
+	
+	export class NodeShell {
+	    id: string;
+	    cluster: string;
+	    node: string;
+	    namespace: string;
+	    pod: string;
+	    container: string;
+	    command: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeShell(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.cluster = source["cluster"];
+	        this.node = source["node"];
+	        this.namespace = source["namespace"];
+	        this.pod = source["pod"];
+	        this.container = source["container"];
+	        this.command = source["command"];
+	    }
+	}
+	export class NodeShellOptions {
+	    image?: string;
+	    namespace?: string;
+	    tolerations?: v1.Toleration[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeShellOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.image = source["image"];
+	        this.namespace = source["namespace"];
+	        this.tolerations = this.convertValues(source["tolerations"], v1.Toleration);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PageOptions {
+	    labelSelector?: string;
+	    fieldSelector?: string;
+	    limit: number;
+	    continue?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PageOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.labelSelector = source["labelSelector"];
+	        this.fieldSelector = source["fieldSelector"];
+	        this.limit = source["limit"];
+	        this.continue = source["continue"];
+	    }
+	}
+	export class PortForwardSession {
+	    id: string;
+	    cluster: string;
+	    namespace: string;
+	    resource: string;
+	    name: string;
+	    pod: string;
+	    localPort: number;
+	    remotePort: number;
+	    status: string;
+	    error?: string;
+	    bytesIn: number;
+	    bytesOut: number;
+	    connections: number;
+	    started: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PortForwardSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.cluster = source["cluster"];
+	        this.namespace = source["namespace"];
+	        this.resource = source["resource"];
+	        this.name = source["name"];
+	        this.pod = source["pod"];
+	        this.localPort = source["localPort"];
+	        this.remotePort = source["remotePort"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.bytesIn = source["bytesIn"];
+	        this.bytesOut = source["bytesOut"];
+	        this.connections = source["connections"];
+	        this.started = source["started"];
+	    }
+	}
+	export class ResourcePage {
+	    items: any[];
+	    continue?: string;
+	    total?: number;
+	    offset: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResourcePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = source["items"];
+	        this.continue = source["continue"];
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	    }
+	}
+	
+	export class ResourceSelectors {
+	    labelSelector?: string;
+	    fieldSelector?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResourceSelectors(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.labelSelector = source["labelSelector"];
+	        this.fieldSelector = source["fieldSelector"];
+	    }
+	}
+	export class ResourceSubscription {
+	    id: string;
+	    event: string;
+	    items: any[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResourceSubscription(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.event = source["event"];
+	        this.items = source["items"];
+	    }
+	}
+	export class TableRow {
+	    name: string;
+	    namespace?: string;
+	    resourceVersion?: string;
+	    cells: any[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TableRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.namespace = source["namespace"];
+	        this.resourceVersion = source["resourceVersion"];
+	        this.cells = source["cells"];
+	    }
+	}
+	export class TableColumn {
+	    name: string;
+	    type: string;
+	    format?: string;
+	    description?: string;
+	    priority: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableColumn(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.format = source["format"];
+	        this.description = source["description"];
+	        this.priority = source["priority"];
+	    }
+	}
+	export class ResourceTable {
+	    columns: TableColumn[];
+	    rows: TableRow[];
+	    continue?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResourceTable(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columns = this.convertValues(source["columns"], TableColumn);
+	        this.rows = this.convertValues(source["rows"], TableRow);
+	        this.continue = source["continue"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RolloutRevision {
+	    revision: number;
+	    name: string;
+	    changeCause?: string;
+	    images: string[];
+	    age: string;
+	    current: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RolloutRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.revision = source["revision"];
+	        this.name = source["name"];
+	        this.changeCause = source["changeCause"];
+	        this.images = source["images"];
+	        this.age = source["age"];
+	        this.current = source["current"];
+	    }
+	}
+	export class RolloutStatus {
+	    phase: string;
+	    message: string;
+	    ready?: string;
+	    desired: number;
+	    updated: number;
+	    available: number;
+	    failingPods?: FailingPod[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RolloutStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.phase = source["phase"];
+	        this.message = source["message"];
+	        this.ready = source["ready"];
+	        this.desired = source["desired"];
+	        this.updated = source["updated"];
+	        this.available = source["available"];
+	        this.failingPods = this.convertValues(source["failingPods"], FailingPod);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RolloutWatch {
+	    id: string;
+	    event: string;
+	    status: RolloutStatus;
+	
+	    static createFrom(source: any = {}) {
+	        return new RolloutWatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.event = source["event"];
+	        this.status = this.convertValues(source["status"], RolloutStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SelectorError {
+	    selector: string;
+	    position: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelectorError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.selector = source["selector"];
+	        this.position = source["position"];
+	        this.message = source["message"];
+	    }
+	}
+	
+	
+	export class WorkloadScale {
+	    replicas: number;
+	    currentReplicas: number;
+	    selector?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkloadScale(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.replicas = source["replicas"];
+	        this.currentReplicas = source["currentReplicas"];
+	        this.selector = source["selector"];
+	    }
+	}
+
+}
+
+export namespace v1 {
+	
+	export class Toleration {
+	    key?: string;
+	    operator?: string;
+	    value?: string;
+	    effect?: string;
+	    tolerationSeconds?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Toleration(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.operator = source["operator"];
+	        this.value = source["value"];
+	        this.effect = source["effect"];
+	        this.tolerationSeconds = source["tolerationSeconds"];
+	    }
+	}
 
 }
 
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// listPageSize is the chunk size used when listing whole collections, as kubectl does.
+	listPageSize = 500
+	// maxListPageSize caps the page size the UI may ask for.
+	maxListPageSize = 5000
+)
+
+// PageOptions selects a page of a resource list.
+// Continue is the cursor returned with the previous page, empty for the first page.
+// The selectors must stay the same while paging.
+type PageOptions struct {
+	ResourceSelectors
+	Limit    int64  `json:"limit"`
+	Continue string `json:"continue,omitempty"`
+}
+
+// ResourcePage is one page of a resource list.
+// Continue is empty on the last page. Total is an estimate of the whole list size,
+// based on the server's remaining item count, and is nil when the server doesn't provide one
+// (e.g. when a field selector is used).
+type ResourcePage struct {
+	Items    []interface{} `json:"items"`
+	Continue string        `json:"continue,omitempty"`
+	Total    *int64        `json:"total,omitempty"`
+	Offset   int64         `json:"offset"`
+}
+
+// continueToken is the cursor handed to the UI: the server's continue token plus
+// the number of items already returned, which the server token does not expose.
+type continueToken struct {
+	Token  string `json:"t"`
+	Offset int64  `json:"o"`
+}
+
+// ListResourcesPage lists a single page of resources. Pass the returned Continue
+// in the next call to get the following page. Paging AllNamespaces needs permission
+// to list the resource cluster-wide.
+func (a *App) ListResourcesPage(clusterName, resourceName, namespace string, opts PageOptions) (*ResourcePage, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceInfo, gvr, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = listPageSize
+	}
+	if limit > maxListPageSize {
+		limit = maxListPageSize
+	}
+
+	var cursor continueToken
+	if opts.Continue != "" {
+		if err := decodeCursor(opts.Continue, &cursor); err != nil {
+			return nil, fmt.Errorf("invalid continue token: %w", err)
+		}
+	}
+
+	resourceClient := resourceInterface(clients.DynamicClient, gvr, resourceInfo.Namespaced, listNamespace(namespace))
+	listOptions := metav1.ListOptions{
+		Limit:    limit,
+		Continue: cursor.Token,
+	}
+	opts.applyTo(&listOptions)
+	list, err := resourceClient.List(context.Background(), listOptions)
+	if err != nil {
+		if errors.IsResourceExpired(err) {
+			return nil, fmt.Errorf("the list changed too much since the first page, start again from the beginning: %w", err)
+		}
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	page := &ResourcePage{Items: []interface{}{}, Offset: cursor.Offset}
+	for _, item := range list.Items {
+		page.Items = append(page.Items, toResourceResponse(schema.GroupKind{Group: gvr.Group, Kind: resourceInfo.Kind}, item))
+	}
+
+	returned := cursor.Offset + int64(len(list.Items))
+	switch {
+	case list.GetContinue() == "":
+		page.Total = &returned
+	case list.GetRemainingItemCount() != nil:
+		total := returned + *list.GetRemainingItemCount()
+		page.Total = &total
+	}
+
+	if list.GetContinue() != "" {
+		page.Continue, err = encodeCursor(continueToken{Token: list.GetContinue(), Offset: returned})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func encodeCursor(cursor continueToken) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value string, cursor *continueToken) error {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cursor)
+}
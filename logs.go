@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// logBatchSize caps how much log output is sent in a single WebSocket message.
+const logBatchSize = 32 * 1024
+
+// podLogOptionsFromQuery builds log options from the /logs query string:
+// follow, tailLines, sinceSeconds, sinceTime (RFC3339), timestamps, previous and limitBytes.
+func podLogOptionsFromQuery(query url.Values) (*corev1.PodLogOptions, error) {
+	opts := &corev1.PodLogOptions{Container: query.Get("container")}
+
+	for name, target := range map[string]*bool{
+		"follow":     &opts.Follow,
+		"timestamps": &opts.Timestamps,
+		"previous":   &opts.Previous,
+	} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*target = parsed
+		}
+	}
+
+	for name, target := range map[string]**int64{
+		"tailLines":    &opts.TailLines,
+		"sinceSeconds": &opts.SinceSeconds,
+		"limitBytes":   &opts.LimitBytes,
+	} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("invalid %s: %q", name, value)
+			}
+			*target = &parsed
+		}
+	}
+
+	if value := query.Get("sinceTime"); value != "" {
+		if opts.SinceSeconds != nil {
+			return nil, fmt.Errorf("sinceSeconds and sinceTime are mutually exclusive")
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime: %w", err)
+		}
+		opts.SinceTime = &metav1.Time{Time: t}
+	}
+
+	return opts, nil
+}
+
+// handleLogsWebSocket streams container logs over a WebSocket.
+// The stream is cancelled as soon as the client disconnects.
+func (a *App) handleLogsWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	query := r.URL.Query()
+	clusterName := query.Get("cluster")
+	namespace := query.Get("namespace")
+	podName := query.Get("pod")
+
+	if clusterName == "" || namespace == "" || podName == "" {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Missing required parameters"))
+		return
+	}
+
+	opts, err := podLogOptionsFromQuery(query)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseUnsupportedData, err.Error()))
+		return
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+
+	stream, err := clients.StreamClientset.CoreV1().Pods(namespace).GetLogs(podName, opts).Stream(ctx)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, fmt.Sprintf("failed to get logs stream: %v", err)))
+		return
+	}
+	defer stream.Close()
+
+	// Reading is required to notice the client going away (e.g. the tab was closed)
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	log.Printf("Streaming logs of %s/%s, container %s (follow=%t)", namespace, podName, opts.Container, opts.Follow)
+	err = streamLogs(ctx, stream, func(data []byte) error {
+		return conn.WriteMessage(websocket.TextMessage, data)
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Log stream error: %v", err)
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "end of log"))
+}
+
+// streamLogs forwards whole lines from the stream to send, batching lines that
+// are already buffered so chatty containers don't produce a message per line.
+func streamLogs(ctx context.Context, stream io.Reader, send func([]byte) error) error {
+	reader := bufio.NewReaderSize(stream, logBatchSize)
+	var batch bytes.Buffer
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		batch.Write(line)
+
+		if batch.Len() > 0 && (err != nil || batch.Len() >= logBatchSize || reader.Buffered() == 0) {
+			if sendErr := send(batch.Bytes()); sendErr != nil {
+				return sendErr
+			}
+			batch.Reset()
+		}
+
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// AllNamespaces selects namespaced resources of every namespace, like kubectl get -A.
+// It can't clash with a real namespace, whose names are DNS labels.
+const AllNamespaces = "*"
+
+// namespaceListConcurrency bounds the parallel lists when falling back to one list per namespace.
+const namespaceListConcurrency = 8
+
+// listNamespace returns the namespace to list in, where "" means the whole cluster.
+func listNamespace(namespace string) string {
+	if namespace == AllNamespaces {
+		return ""
+	}
+	return namespace
+}
+
+// listAllNamespaces lists a namespaced resource across the cluster. Users that may not
+// list it cluster-wide get the items of each namespace GetNamespaces returns for them,
+// which also covers its fallback when namespaces can't be listed.
+func (a *App) listAllNamespaces(clusterName string, clients *KubeClients, resourceInfo ResourceInfo, gvr schema.GroupVersionResource, selectors ResourceSelectors) ([]interface{}, error) {
+	responses, err := listResources(clients, resourceInfo, gvr, "", selectors)
+	if !errors.IsForbidden(err) {
+		return responses, err
+	}
+
+	namespaces, nsErr := a.GetNamespaces(clusterName)
+	if nsErr != nil {
+		return nil, err
+	}
+	log.Printf("No permission to list %s in all namespaces of %s, listing %d namespaces one by one", resourceInfo.Name, clusterName, len(namespaces))
+
+	results := make([][]interface{}, len(namespaces))
+	errs := make([]error, len(namespaces))
+	semaphore := make(chan struct{}, namespaceListConcurrency)
+	var wg sync.WaitGroup
+	for i, namespace := range namespaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results[i], errs[i] = listResources(clients, resourceInfo, gvr, namespace, selectors)
+		}()
+	}
+	wg.Wait()
+
+	// Namespaces the user can't read are skipped, the list fails only if none could be read
+	responses = []interface{}{}
+	listed := 0
+	for i, namespace := range namespaces {
+		if errs[i] != nil {
+			if !errors.IsForbidden(errs[i]) {
+				return nil, fmt.Errorf("namespace %s: %w", namespace, errs[i])
+			}
+			continue
+		}
+		listed++
+		responses = append(responses, results[i]...)
+	}
+	if listed == 0 {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// checkClusterWideList fails if the resource can't be listed across all namespaces,
+// which a cluster-wide watch needs.
+func checkClusterWideList(ctx context.Context, clients *KubeClients, gvr schema.GroupVersionResource) error {
+	_, err := clients.DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{Limit: 1})
+	return err
+}
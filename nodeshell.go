@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// defaultNodeShellImage needs sh and nsenter, busybox has both.
+	defaultNodeShellImage = "docker.io/library/busybox:1.36"
+	// defaultNodeShellNamespace usually allows privileged pods, unlike namespaces that
+	// enforce the baseline or restricted Pod Security Standards.
+	defaultNodeShellNamespace = "kube-system"
+	nodeShellContainer        = "shell"
+	// nodeShellLabel marks node shell pods, e.g. to find ones left over after a crash.
+	nodeShellLabel = "kubeplorer.io/node-shell"
+	// nodeShellStartTimeout is the time the pod gets to pull its image and start.
+	nodeShellStartTimeout  = 2 * time.Minute
+	nodeShellDeleteTimeout = 10 * time.Second
+	// nodeShellAttachTimeout is the time a started shell waits for its terminal session,
+	// so the pod doesn't keep running when the terminal never connects.
+	nodeShellAttachTimeout = time.Minute
+)
+
+// nodeShellCommand enters the namespaces of the node's init process, so the shell
+// sees the node's filesystem, hostname, IPC and network.
+var nodeShellCommand = []string{"nsenter", "-t", "1", "-m", "-u", "-i", "-n", "/bin/sh"}
+
+// NodeShellOptions configures the pod of a node shell. Clusters without internet
+// access need an Image from their own registry. Tolerations default to tolerating
+// all taints, so that the pod can run on any node; set them to schedule it only where
+// the given taints are tolerated. Namespace defaults to "kube-system", it has to allow
+// privileged pods.
+type NodeShellOptions struct {
+	Image       string              `json:"image,omitempty"`
+	Namespace   string              `json:"namespace,omitempty"`
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// NodeShell is a running node shell pod. Connect to it through the /terminal WebSocket
+// with the pod, container and comma separated command.
+type NodeShell struct {
+	ID        string `json:"id"`
+	Cluster   string `json:"cluster"`
+	Node      string `json:"node"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Command   string `json:"command"`
+}
+
+// nodeShellRegistry holds the pods of node shells, so they are deleted when their
+// terminal session ends, when no session attaches to them in time, or when the app exits.
+type nodeShellRegistry struct {
+	mu       sync.Mutex
+	shells   map[string]NodeShell
+	detached map[string]*time.Timer // Shells waiting for their terminal session
+	nextID   int64
+}
+
+func newNodeShellRegistry() *nodeShellRegistry {
+	return &nodeShellRegistry{shells: make(map[string]NodeShell), detached: make(map[string]*time.Timer)}
+}
+
+func (r *nodeShellRegistry) add(shell NodeShell) NodeShell {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	shell.ID = strconv.FormatInt(r.nextID, 10)
+	r.shells[shell.ID] = shell
+	return shell
+}
+
+// awaitAttach calls expire unless a terminal session attaches to the shell within timeout.
+func (r *nodeShellRegistry) awaitAttach(id string, timeout time.Duration, expire func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detached[id] = time.AfterFunc(timeout, func() {
+		r.mu.Lock()
+		_, pending := r.detached[id]
+		delete(r.detached, id)
+		r.mu.Unlock()
+		if pending {
+			expire()
+		}
+	})
+}
+
+// attach marks the shell of a pod as used by a terminal session.
+func (r *nodeShellRegistry) attach(clusterName, namespace, podName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.find(clusterName, namespace, podName); ok {
+		r.forget(id, false)
+	}
+}
+
+// remove unregisters the shell of a pod, ok is false if the pod is no node shell.
+func (r *nodeShellRegistry) remove(clusterName, namespace, podName string) (NodeShell, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.find(clusterName, namespace, podName)
+	if !ok {
+		return NodeShell{}, false
+	}
+	shell := r.shells[id]
+	r.forget(id, true)
+	return shell, true
+}
+
+func (r *nodeShellRegistry) removeByID(id string) (NodeShell, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	shell, ok := r.shells[id]
+	r.forget(id, true)
+	return shell, ok
+}
+
+func (r *nodeShellRegistry) removeAll() []NodeShell {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	shells := make([]NodeShell, 0, len(r.shells))
+	for id, shell := range r.shells {
+		shells = append(shells, shell)
+		r.forget(id, true)
+	}
+	return shells
+}
+
+func (r *nodeShellRegistry) find(clusterName, namespace, podName string) (string, bool) {
+	for id, shell := range r.shells {
+		if shell.Cluster == clusterName && shell.Namespace == namespace && shell.Pod == podName {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// forget stops waiting for the shell's terminal session and, with remove, unregisters it.
+func (r *nodeShellRegistry) forget(id string, remove bool) {
+	if timer, ok := r.detached[id]; ok {
+		timer.Stop()
+		delete(r.detached, id)
+	}
+	if remove {
+		delete(r.shells, id)
+	}
+}
+
+// StartNodeShell creates a privileged pod on the node that shares its PID and network
+// namespaces and waits until it runs. The pod is deleted when the terminal session to
+// it ends, when no session attaches to it within a minute, when StopNodeShell is called
+// or when the app exits.
+func (a *App) StartNodeShell(clusterName, nodeName string, options NodeShellOptions) (*NodeShell, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodeShellStartTimeout)
+	defer cancel()
+
+	if _, err := clients.Clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get node %q: %w", nodeName, err)
+	}
+
+	namespace := nodeShellNamespace(options)
+	pod, err := clients.Clientset.CoreV1().Pods(namespace).Create(ctx, nodeShellPod(nodeName, options), metav1.CreateOptions{})
+	if errors.IsForbidden(err) && strings.Contains(err.Error(), "PodSecurity") {
+		return nil, fmt.Errorf("namespace %q doesn't allow privileged pods, choose a namespace that enforces the privileged Pod Security Standard, e.g. kube-system, in the shell settings: %w", namespace, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create node shell pod: %w", err)
+	}
+	shell := a.nodeShells.add(NodeShell{
+		Cluster:   clusterName,
+		Node:      nodeName,
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Container: nodeShellContainer,
+		Command:   strings.Join(nodeShellCommand, ","),
+	})
+	log.Printf("Created node shell pod %s/%s on node %s in %s", pod.Namespace, pod.Name, nodeName, clusterName)
+
+	if err := waitForNodeShell(ctx, clients, pod.Namespace, pod.Name); err != nil {
+		a.StopNodeShell(shell.ID)
+		return nil, err
+	}
+	a.nodeShells.awaitAttach(shell.ID, nodeShellAttachTimeout, func() {
+		log.Printf("No terminal session attached to node shell pod %s/%s within %s", pod.Namespace, pod.Name, nodeShellAttachTimeout)
+		a.StopNodeShell(shell.ID)
+	})
+	return &shell, nil
+}
+
+// StopNodeShell deletes the pod of a node shell.
+func (a *App) StopNodeShell(shellID string) error {
+	shell, ok := a.nodeShells.removeByID(shellID)
+	if !ok {
+		return fmt.Errorf("node shell %s not found", shellID)
+	}
+	return a.deleteNodeShell(shell)
+}
+
+// endNodeShell deletes the pod if it is a node shell, once its terminal session ended.
+func (a *App) endNodeShell(clusterName, namespace, podName string) {
+	if shell, ok := a.nodeShells.remove(clusterName, namespace, podName); ok {
+		a.deleteNodeShell(shell)
+	}
+}
+
+// stopNodeShells deletes the pods of all node shells when the app exits.
+func (a *App) stopNodeShells() {
+	var wg sync.WaitGroup
+	for _, shell := range a.nodeShells.removeAll() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.deleteNodeShell(shell)
+		}()
+	}
+	wg.Wait()
+}
+
+func (a *App) deleteNodeShell(shell NodeShell) error {
+	clients, err := a.getKubeClients(shell.Cluster)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), nodeShellDeleteTimeout)
+	defer cancel()
+
+	gracePeriod := int64(0)
+	err = clients.Clientset.CoreV1().Pods(shell.Namespace).Delete(ctx, shell.Pod, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	if err != nil && !errors.IsNotFound(err) {
+		log.Printf("Failed to delete node shell pod %s/%s: %v", shell.Namespace, shell.Pod, err)
+		return fmt.Errorf("failed to delete node shell pod %q: %w", shell.Pod, err)
+	}
+	log.Printf("Deleted node shell pod %s/%s", shell.Namespace, shell.Pod)
+	return nil
+}
+
+func nodeShellNamespace(options NodeShellOptions) string {
+	if options.Namespace != "" {
+		return options.Namespace
+	}
+	return defaultNodeShellNamespace
+}
+
+// nodeShellPod builds a privileged pod pinned to the node. Its container only sleeps,
+// the shell is started with nsenter through exec.
+func nodeShellPod(nodeName string, options NodeShellOptions) *corev1.Pod {
+	image := options.Image
+	if image == "" {
+		image = defaultNodeShellImage
+	}
+	tolerations := options.Tolerations
+	if tolerations == nil {
+		tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	}
+	privileged := true
+	automountToken := false
+	gracePeriod := int64(0)
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "node-shell-",
+			Labels:       map[string]string{nodeShellLabel: "true"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                      nodeName,
+			HostPID:                       true,
+			HostNetwork:                   true,
+			HostIPC:                       true,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			Tolerations:                   tolerations,
+			AutomountServiceAccountToken:  &automountToken,
+			TerminationGracePeriodSeconds: &gracePeriod,
+			Containers: []corev1.Container{{
+				Name:    nodeShellContainer,
+				Image:   image,
+				Command: []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 3600 & wait; done"},
+				SecurityContext: &corev1.SecurityContext{
+					Privileged: &privileged,
+				},
+			}},
+		},
+	}
+}
+
+// waitForNodeShell waits until the pod runs, failing early if it can't start.
+func waitForNodeShell(ctx context.Context, clients *KubeClients, namespace, podName string) error {
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		pod, err := clients.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return true, nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, fmt.Errorf("node shell pod %q ended: %s", podName, summarizePod(pod, time.Now()).status)
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil && isFatalWaitingReason(waiting.Reason) {
+				return false, fmt.Errorf("node shell pod %q can't start: %s: %s", podName, waiting.Reason, waiting.Message)
+			}
+		}
+		return false, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("node shell pod %q didn't start within %s", podName, nodeShellStartTimeout)
+	}
+	return err
+}
+
+// isFatalWaitingReason tells waiting reasons a container won't recover from by itself.
+func isFatalWaitingReason(reason string) bool {
+	switch reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError":
+		return true
+	}
+	return false
+}
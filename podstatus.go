@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// nodeUnreachablePodReason is the pod reason set by the node controller for pods of lost nodes.
+const nodeUnreachablePodReason = "NodeLost"
+
+// podSummary holds the values kubectl get pods prints for a pod.
+type podSummary struct {
+	status         string
+	ready          string
+	restarts       int32
+	restartsText   string // e.g. "3 (5m ago)"
+	readinessGates string // e.g. "1/2", empty without readiness gates
+	nominatedNode  string
+	containers     []string
+}
+
+// summarizePod reproduces the STATUS, READY and RESTARTS columns of kubectl get pods,
+// including init container progress, restartable init (sidecar) containers,
+// terminating and lost pods, and the time of the last restart.
+func summarizePod(p *corev1.Pod, now time.Time) podSummary {
+	var restarts, sidecarRestarts int32
+	var lastRestart, lastSidecarRestart time.Time
+	totalContainers := len(p.Spec.Containers)
+	readyContainers := 0
+
+	reason := string(p.Status.Phase)
+	if p.Status.Reason != "" {
+		reason = p.Status.Reason
+	}
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Reason == corev1.PodReasonSchedulingGated {
+			reason = corev1.PodReasonSchedulingGated
+		}
+	}
+
+	initContainers := make(map[string]*corev1.Container, len(p.Spec.InitContainers))
+	for i := range p.Spec.InitContainers {
+		initContainers[p.Spec.InitContainers[i].Name] = &p.Spec.InitContainers[i]
+		if isRestartableInitContainer(&p.Spec.InitContainers[i]) {
+			totalContainers++
+		}
+	}
+
+	initializing := false
+	for i, container := range p.Status.InitContainerStatuses {
+		restarts += container.RestartCount
+		lastRestart = laterTermination(lastRestart, container)
+		sidecar := isRestartableInitContainer(initContainers[container.Name])
+		if sidecar {
+			sidecarRestarts += container.RestartCount
+			lastSidecarRestart = laterTermination(lastSidecarRestart, container)
+		}
+
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case sidecar && container.Started != nil && *container.Started:
+			if container.Ready {
+				readyContainers++
+			}
+			continue
+		case container.State.Terminated != nil:
+			// Initialization failed
+			reason = "Init:" + terminatedReason(container.State.Terminated)
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + container.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(p.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing || hasPodCondition(p, corev1.PodInitialized) {
+		// Restarts of completed init containers don't count once the pod is initialized
+		restarts = sidecarRestarts
+		lastRestart = lastSidecarRestart
+		hasRunning := false
+		for i := len(p.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			container := p.Status.ContainerStatuses[i]
+			restarts += container.RestartCount
+			lastRestart = laterTermination(lastRestart, container)
+
+			switch {
+			case container.State.Waiting != nil && container.State.Waiting.Reason != "":
+				reason = container.State.Waiting.Reason
+			case container.State.Terminated != nil:
+				reason = terminatedReason(container.State.Terminated)
+			case container.Ready && container.State.Running != nil:
+				hasRunning = true
+				readyContainers++
+			}
+		}
+
+		// A completed container doesn't make the pod completed while others still run
+		if reason == "Completed" && hasRunning {
+			if hasPodCondition(p, corev1.PodReady) {
+				reason = "Running"
+			} else {
+				reason = "NotReady"
+			}
+		}
+	}
+
+	if p.DeletionTimestamp != nil && p.Status.Reason == nodeUnreachablePodReason {
+		reason = "Unknown"
+	} else if p.DeletionTimestamp != nil && p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+		reason = "Terminating"
+	}
+
+	restartsText := strconv.Itoa(int(restarts))
+	if restarts != 0 && !lastRestart.IsZero() {
+		restartsText = fmt.Sprintf("%d (%s ago)", restarts, duration.HumanDuration(now.Sub(lastRestart)))
+	}
+
+	var containers []string
+	for _, c := range p.Spec.InitContainers {
+		containers = append(containers, c.Name)
+	}
+	for _, c := range p.Spec.Containers {
+		containers = append(containers, c.Name)
+	}
+
+	return podSummary{
+		status:         reason,
+		ready:          fmt.Sprintf("%d/%d", readyContainers, totalContainers),
+		restarts:       restarts,
+		restartsText:   restartsText,
+		readinessGates: readinessGates(p),
+		nominatedNode:  p.Status.NominatedNodeName,
+		containers:     containers,
+	}
+}
+
+// isRestartableInitContainer reports whether the init container is a sidecar
+// that keeps running next to the regular containers.
+func isRestartableInitContainer(c *corev1.Container) bool {
+	return c != nil && c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+// terminatedReason returns the reason of a terminated container, or its signal or exit code without one.
+func terminatedReason(state *corev1.ContainerStateTerminated) string {
+	switch {
+	case state.Reason != "":
+		return state.Reason
+	case state.Signal != 0:
+		return fmt.Sprintf("Signal:%d", state.Signal)
+	default:
+		return fmt.Sprintf("ExitCode:%d", state.ExitCode)
+	}
+}
+
+// laterTermination returns the later of last and the time the container last terminated.
+func laterTermination(last time.Time, status corev1.ContainerStatus) time.Time {
+	if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.FinishedAt.Time.After(last) {
+		return terminated.FinishedAt.Time
+	}
+	return last
+}
+
+func hasPodCondition(p *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// readinessGates returns how many of the pod's readiness gates are met, like "1/2".
+func readinessGates(p *corev1.Pod) string {
+	if len(p.Spec.ReadinessGates) == 0 {
+		return ""
+	}
+	met := 0
+	for _, gate := range p.Spec.ReadinessGates {
+		if hasPodCondition(p, gate.ConditionType) {
+			met++
+		}
+	}
+	return fmt.Sprintf("%d/%d", met, len(p.Spec.ReadinessGates))
+}
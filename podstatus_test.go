@@ -0,0 +1,281 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizePod(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	deleted := metav1.NewTime(now.Add(-time.Minute))
+	always := corev1.ContainerRestartPolicyAlways
+
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	completed := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}
+	waiting := func(reason string) corev1.ContainerState {
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+	}
+	terminatedAt := func(at time.Time) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, FinishedAt: metav1.NewTime(at)}}
+	}
+	containers := func(names ...string) []corev1.Container {
+		var c []corev1.Container
+		for _, name := range names {
+			c = append(c, corev1.Container{Name: name})
+		}
+		return c
+	}
+	condition := func(conditionType corev1.PodConditionType, status corev1.ConditionStatus) corev1.PodCondition {
+		return corev1.PodCondition{Type: conditionType, Status: status}
+	}
+
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want podSummary
+	}{
+		{
+			name: "running",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app", "proxy")},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "app", Ready: true, State: running},
+						{Name: "proxy", Ready: false, State: running},
+					},
+				},
+			},
+			want: podSummary{status: "Running", ready: "1/2", restartsText: "0"},
+		},
+		{
+			name: "init container progress",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("migrate", "seed"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{Name: "migrate", State: completed},
+						{Name: "seed", State: running},
+					},
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "app", State: waiting("PodInitializing")}},
+				},
+			},
+			want: podSummary{status: "Init:1/2", ready: "0/1", restartsText: "0"},
+		},
+		{
+			name: "init container waiting reason",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("migrate"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{{Name: "migrate", State: waiting("ImagePullBackOff")}},
+				},
+			},
+			want: podSummary{status: "Init:ImagePullBackOff", ready: "0/1", restartsText: "0"},
+		},
+		{
+			name: "init container failed",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: containers("migrate"), Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{{
+						Name:                 "migrate",
+						RestartCount:         2,
+						State:                corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 3}},
+						LastTerminationState: terminatedAt(now.Add(-5 * time.Minute)),
+					}},
+				},
+			},
+			want: podSummary{status: "Init:ExitCode:3", ready: "0/1", restarts: 2, restartsText: "2 (5m ago)"},
+		},
+		{
+			name: "restartable sidecar",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "mesh", RestartPolicy: &always}},
+					Containers:     containers("app"),
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{condition(corev1.PodInitialized, corev1.ConditionTrue)},
+					InitContainerStatuses: []corev1.ContainerStatus{{
+						Name:                 "mesh",
+						Ready:                true,
+						Started:              ptr(true),
+						RestartCount:         1,
+						State:                running,
+						LastTerminationState: terminatedAt(now.Add(-10 * time.Minute)),
+					}},
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true, State: running}},
+				},
+			},
+			want: podSummary{status: "Running", ready: "2/2", restarts: 1, restartsText: "1 (10m ago)"},
+		},
+		{
+			name: "crash loop with last restart",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:                 "app",
+						RestartCount:         4,
+						State:                waiting("CrashLoopBackOff"),
+						LastTerminationState: terminatedAt(now.Add(-90 * time.Second)),
+					}},
+				},
+			},
+			want: podSummary{status: "CrashLoopBackOff", ready: "0/1", restarts: 4, restartsText: "4 (90s ago)"},
+		},
+		{
+			name: "completed container next to a running one, pod ready",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app", "job")},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{condition(corev1.PodReady, corev1.ConditionTrue)},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "app", Ready: true, State: running},
+						{Name: "job", State: completed},
+					},
+				},
+			},
+			want: podSummary{status: "Running", ready: "1/2", restartsText: "0"},
+		},
+		{
+			name: "completed container next to a running one, pod not ready",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app", "job")},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{condition(corev1.PodReady, corev1.ConditionFalse)},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "app", Ready: true, State: running},
+						{Name: "job", State: completed},
+					},
+				},
+			},
+			want: podSummary{status: "NotReady", ready: "1/2", restartsText: "0"},
+		},
+		{
+			name: "succeeded",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("job")},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodSucceeded,
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "job", State: completed}},
+				},
+			},
+			want: podSummary{status: "Completed", ready: "0/1", restartsText: "0"},
+		},
+		{
+			name: "scheduling gated",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					Conditions: []corev1.PodCondition{{
+						Type:   corev1.PodScheduled,
+						Status: corev1.ConditionFalse,
+						Reason: corev1.PodReasonSchedulingGated,
+					}},
+				},
+			},
+			want: podSummary{status: "SchedulingGated", ready: "0/1", restartsText: "0"},
+		},
+		{
+			name: "terminating",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+				Spec:       corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true, State: running}},
+				},
+			},
+			want: podSummary{status: "Terminating", ready: "1/1", restartsText: "0"},
+		},
+		{
+			name: "deleted after completion",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+				Spec:       corev1.PodSpec{Containers: containers("job")},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodSucceeded,
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "job", State: completed}},
+				},
+			},
+			want: podSummary{status: "Completed", ready: "0/1", restartsText: "0"},
+		},
+		{
+			name: "lost node",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+				Spec:       corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					Reason:            nodeUnreachablePodReason,
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true, State: running}},
+				},
+			},
+			want: podSummary{status: "Unknown", ready: "1/1", restartsText: "0"},
+		},
+		{
+			name: "readiness gates",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: containers("app"),
+					ReadinessGates: []corev1.PodReadinessGate{
+						{ConditionType: "target-health.elbv2.k8s.aws/web"},
+						{ConditionType: "example.com/warm"},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						condition("target-health.elbv2.k8s.aws/web", corev1.ConditionTrue),
+						condition("example.com/warm", corev1.ConditionFalse),
+					},
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true, State: running}},
+				},
+			},
+			want: podSummary{status: "Running", ready: "1/1", restartsText: "0", readinessGates: "1/2"},
+		},
+		{
+			name: "nominated node",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: containers("app")},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodPending,
+					NominatedNodeName: "node-2",
+				},
+			},
+			want: podSummary{status: "Pending", ready: "0/1", restartsText: "0", nominatedNode: "node-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizePod(&tt.pod, now)
+			if got.status != tt.want.status {
+				t.Errorf("status = %q, want %q", got.status, tt.want.status)
+			}
+			if got.ready != tt.want.ready {
+				t.Errorf("ready = %q, want %q", got.ready, tt.want.ready)
+			}
+			if got.restarts != tt.want.restarts || got.restartsText != tt.want.restartsText {
+				t.Errorf("restarts = %d %q, want %d %q", got.restarts, got.restartsText, tt.want.restarts, tt.want.restartsText)
+			}
+			if got.readinessGates != tt.want.readinessGates {
+				t.Errorf("readinessGates = %q, want %q", got.readinessGates, tt.want.readinessGates)
+			}
+			if got.nominatedNode != tt.want.nominatedNode {
+				t.Errorf("nominatedNode = %q, want %q", got.nominatedNode, tt.want.nominatedNode)
+			}
+		})
+	}
+}
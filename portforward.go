@@ -0,0 +1,636 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// portForwardMaxBackoff caps the wait between reconnects to a replaced pod.
+	portForwardMaxBackoff   = 30 * time.Second
+	portForwardReadyTimeout = 30 * time.Second
+	// portForwardWaitTimeout bounds how long a local connection waits for a reconnect.
+	portForwardWaitTimeout = 30 * time.Second
+)
+
+// Port forward states.
+const (
+	portForwardConnecting = "connecting"
+	portForwardActive     = "active"
+	portForwardRetrying   = "reconnecting"
+	portForwardFailed     = "failed"
+)
+
+// errPortForwardEnded marks errors after which reconnecting is pointless, e.g. when the
+// forwarded pod is gone and nothing tells which pod replaced it.
+var errPortForwardEnded = errors.New("port forward ended")
+
+// podInstanceLabels differ between a pod and its replacement, so they are left out when
+// a replacement is looked up by the pod's labels.
+var podInstanceLabels = []string{
+	appsv1.DefaultDeploymentUniqueLabelKey,
+	appsv1.ControllerRevisionHashLabelKey,
+	appsv1.StatefulSetPodNameLabel,
+	appsv1.PodIndexLabel,
+	batchv1.ControllerUidLabel,
+	batchv1.JobCompletionIndexAnnotation,
+	"controller-uid",
+}
+
+// PortForwardSession describes a port forward. Resource and Name are the target as
+// requested, Pod is the pod currently forwarded to, which changes when it is replaced.
+// A session that can't find a pod to forward to anymore stays listed with Status "failed"
+// and the Error until it is stopped. BytesIn counts bytes received from the pod, BytesOut
+// bytes sent to it.
+type PortForwardSession struct {
+	ID          string `json:"id"`
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace"`
+	Resource    string `json:"resource"`
+	Name        string `json:"name"`
+	Pod         string `json:"pod"`
+	LocalPort   int    `json:"localPort"`
+	RemotePort  int    `json:"remotePort"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	BytesIn     int64  `json:"bytesIn"`
+	BytesOut    int64  `json:"bytesOut"`
+	Connections int64  `json:"connections"`
+	Started     string `json:"started"`
+}
+
+// portForwardRegistry holds the running port forwards.
+type portForwardRegistry struct {
+	mu       sync.Mutex
+	forwards map[string]*portForward
+	nextID   int64
+}
+
+func newPortForwardRegistry() *portForwardRegistry {
+	return &portForwardRegistry{forwards: make(map[string]*portForward)}
+}
+
+// portForward keeps a local listener open for its whole lifetime and proxies each
+// connection to a client-go port forwarder of the current pod. The forwarder listens on
+// a random loopback port and is replaced when its pod goes away, so the local port
+// the user connects to stays the same across reconnects.
+type portForward struct {
+	clients  *KubeClients
+	listener net.Listener
+	cancel   context.CancelFunc
+	target   *unstructured.Unstructured // Service or workload, nil for pods
+	pod      *corev1.Pod                // Pod forwarded to for pod targets, to find its replacement
+
+	bytesIn     atomic.Int64
+	bytesOut    atomic.Int64
+	connections atomic.Int64
+
+	mu           sync.Mutex
+	session      PortForwardSession
+	backendPort  int           // Local port of the current forwarder, 0 while reconnecting
+	backendReady chan struct{} // Closed once backendPort is set
+}
+
+func (f *portForward) snapshot() PortForwardSession {
+	f.mu.Lock()
+	session := f.session
+	f.mu.Unlock()
+	session.BytesIn = f.bytesIn.Load()
+	session.BytesOut = f.bytesOut.Load()
+	session.Connections = f.connections.Load()
+	return session
+}
+
+// setBackend sets the local port of the current forwarder, waking up the connections
+// waiting for it, or 0 when it is lost.
+func (f *portForward) setBackend(port int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case port != 0 && f.backendPort == 0:
+		close(f.backendReady)
+	case port == 0 && f.backendPort != 0:
+		f.backendReady = make(chan struct{})
+	}
+	f.backendPort = port
+}
+
+// waitBackend returns the local port of the current forwarder, waiting for a reconnect
+// while there is none.
+func (f *portForward) waitBackend(ctx context.Context) (int, error) {
+	timer := time.NewTimer(portForwardWaitTimeout)
+	defer timer.Stop()
+	for {
+		f.mu.Lock()
+		port, ready := f.backendPort, f.backendReady
+		f.mu.Unlock()
+		if port != 0 {
+			return port, nil
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-timer.C:
+			return 0, fmt.Errorf("no connection to a pod after %v", portForwardWaitTimeout)
+		}
+	}
+}
+
+func (f *portForward) setStatus(status, pod string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.session.Status = status
+	if pod != "" {
+		f.session.Pod = pod
+	}
+	f.session.Error = ""
+	if err != nil {
+		f.session.Error = err.Error()
+	}
+}
+
+// StartPortForward forwards a local port to a port of a pod, or of a ready pod of a
+// service or workload, like kubectl port-forward. For services remotePort is a service
+// port, mapped to the pod's target port. A localPort of 0 picks a free port.
+func (a *App) StartPortForward(clusterName, namespace, resourceName, name string, remotePort, localPort int) (*PortForwardSession, error) {
+	if remotePort <= 0 || remotePort > 65535 {
+		return nil, fmt.Errorf("invalid remote port %d", remotePort)
+	}
+	if localPort < 0 || localPort > 65535 {
+		return nil, fmt.Errorf("invalid local port %d", localPort)
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	resourceInfo, gvr, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &portForward{clients: clients, backendReady: make(chan struct{})}
+	if resourceInfo.Kind != "Pod" || resourceInfo.Group != "" {
+		target, err := clients.DynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %q: %w", resourceName, name, err)
+		}
+		if _, err := workloadPodSelector(target); err != nil {
+			return nil, err
+		}
+		f.target = target
+	}
+
+	// Resolve the pod first, so that a wrong target fails the call instead of the session
+	pod, podPort, err := f.resolvePod(context.Background(), namespace, name, remotePort)
+	if err != nil {
+		return nil, err
+	}
+
+	f.listener, err = net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on local port %d: %w", localPort, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	a.portForwards.mu.Lock()
+	a.portForwards.nextID++
+	f.session = PortForwardSession{
+		ID:         strconv.FormatInt(a.portForwards.nextID, 10),
+		Cluster:    clusterName,
+		Namespace:  namespace,
+		Resource:   resourceInfo.Name,
+		Name:       name,
+		Pod:        pod,
+		LocalPort:  f.listener.Addr().(*net.TCPAddr).Port,
+		RemotePort: remotePort,
+		Status:     portForwardConnecting,
+		Started:    time.Now().Format(timeFormat),
+	}
+	a.portForwards.forwards[f.session.ID] = f
+	a.portForwards.mu.Unlock()
+
+	go f.accept(ctx)
+	go f.run(ctx, pod, podPort)
+
+	log.Printf("Forwarding 127.0.0.1:%d to %s/%s %s port %d in %s", f.session.LocalPort, namespace, resourceInfo.Name, name, remotePort, clusterName)
+	session := f.snapshot()
+	return &session, nil
+}
+
+// ListPortForwards returns the running port forwards with their byte counters.
+func (a *App) ListPortForwards() []PortForwardSession {
+	a.portForwards.mu.Lock()
+	defer a.portForwards.mu.Unlock()
+
+	sessions := []PortForwardSession{}
+	for _, f := range a.portForwards.forwards {
+		sessions = append(sessions, f.snapshot())
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		idI, _ := strconv.Atoi(sessions[i].ID)
+		idJ, _ := strconv.Atoi(sessions[j].ID)
+		return idI < idJ
+	})
+	return sessions
+}
+
+// StopPortForward stops a port forward and closes its local port.
+func (a *App) StopPortForward(sessionID string) error {
+	a.portForwards.mu.Lock()
+	f, ok := a.portForwards.forwards[sessionID]
+	delete(a.portForwards.forwards, sessionID)
+	a.portForwards.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("port forward %s not found", sessionID)
+	}
+	f.stop()
+	log.Printf("Stopped port forward %s on local port %d", sessionID, f.session.LocalPort)
+	return nil
+}
+
+// stopAll stops all port forwards when the app exits.
+func (r *portForwardRegistry) stopAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, f := range r.forwards {
+		f.stop()
+		delete(r.forwards, id)
+	}
+}
+
+func (f *portForward) stop() {
+	f.cancel()
+	f.listener.Close()
+}
+
+// run keeps a forwarder to the current pod running, resolving a new pod with backoff
+// whenever the connection to the pod is lost.
+func (f *portForward) run(ctx context.Context, pod string, podPort int) {
+	backoff := time.Second
+	for {
+		connected, err := f.forward(ctx, pod, podPort)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = time.Second
+		}
+		log.Printf("Port forward to %s lost: %v", pod, err)
+		f.setBackend(0)
+		f.setStatus(portForwardRetrying, "", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, portForwardMaxBackoff)
+
+		newPod, newPort, err := f.resolvePod(ctx, f.session.Namespace, f.session.Name, f.session.RemotePort)
+		if errors.Is(err, errPortForwardEnded) {
+			log.Printf("Port forward %s on local port %d ended: %v", f.session.ID, f.session.LocalPort, err)
+			f.setStatus(portForwardFailed, "", err)
+			f.stop()
+			return
+		}
+		if err != nil {
+			f.setStatus(portForwardRetrying, "", err)
+			continue
+		}
+		pod, podPort = newPod, newPort
+	}
+}
+
+// forward runs a client-go port forwarder to the pod until the connection is lost.
+// It reports whether the forwarder got ready.
+func (f *portForward) forward(ctx context.Context, pod string, podPort int) (bool, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(f.clients.RestConfig)
+	if err != nil {
+		return false, err
+	}
+	url := f.clients.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(f.session.Namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", podPort)}, stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return false, err
+	}
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- forwarder.ForwardPorts() }()
+	defer close(stopChan)
+
+	select {
+	case <-readyChan:
+	case err := <-errChan:
+		return false, err
+	case <-time.After(portForwardReadyTimeout):
+		return false, fmt.Errorf("timed out connecting to pod %s", pod)
+	case <-ctx.Done():
+		return false, nil
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		return false, fmt.Errorf("forwarder has no local port: %v", err)
+	}
+	f.setBackend(int(ports[0].Local))
+	f.setStatus(portForwardActive, pod, nil)
+
+	select {
+	case err := <-errChan:
+		if err == nil {
+			err = fmt.Errorf("connection to pod %s closed", pod)
+		}
+		return true, err
+	case <-ctx.Done():
+		return true, nil
+	}
+}
+
+// accept proxies local connections to the current forwarder, counting the bytes.
+func (f *portForward) accept(ctx context.Context) {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Port forward listener on %s failed: %v", f.listener.Addr(), err)
+			}
+			return
+		}
+		go f.proxy(ctx, conn)
+	}
+}
+
+// proxy connects a local connection to the current forwarder. Connections accepted while
+// the forwarder reconnects wait for it instead of being closed right away.
+func (f *portForward) proxy(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	port, err := f.waitBackend(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Port forward %s closes a connection from %s: %v", f.session.ID, conn.RemoteAddr(), err)
+		}
+		return
+	}
+	backend, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return
+	}
+	defer backend.Close()
+	f.connections.Add(1)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		copyCounting(backend, conn, &f.bytesOut)
+		done <- struct{}{}
+	}()
+	go func() {
+		copyCounting(conn, backend, &f.bytesIn)
+		done <- struct{}{}
+	}()
+	// Either side closing ends the connection
+	<-done
+}
+
+func copyCounting(dst io.Writer, src io.Reader, counter *atomic.Int64) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return
+			}
+			counter.Add(int64(n))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// resolvePod returns the pod to forward to and the container port. A Service or
+// workload target resolves to one of its running and ready pods, a pod target to the
+// pod itself or, once a controller replaced it, to its replacement.
+func (f *portForward) resolvePod(ctx context.Context, namespace, name string, remotePort int) (string, int, error) {
+	if f.target == nil {
+		pod, err := f.podOrReplacement(ctx, namespace, name)
+		if err != nil {
+			return "", 0, err
+		}
+		return pod.Name, remotePort, nil
+	}
+
+	selector, err := workloadPodSelector(f.target)
+	if err != nil {
+		return "", 0, err
+	}
+	pod, err := f.readyPod(ctx, namespace, selector)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list pods of %s %q: %w", f.target.GetKind(), name, err)
+	}
+	if pod == nil {
+		return "", 0, fmt.Errorf("%s %q has no ready pods", f.target.GetKind(), name)
+	}
+
+	if f.target.GetKind() != "Service" {
+		return pod.Name, remotePort, nil
+	}
+	podPort, err := serviceTargetPort(f.target, pod, remotePort)
+	if err != nil {
+		return "", 0, err
+	}
+	return pod.Name, podPort, nil
+}
+
+// podOrReplacement returns the pod last forwarded to while it runs. Once it is gone or
+// ending, the replacement is one of the ready pods of its controller, or of its labels for
+// pods without a known controller.
+func (f *portForward) podOrReplacement(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+	if f.pod != nil {
+		name = f.pod.Name
+	}
+	pod, err := f.clients.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil && pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning:
+		f.pod = pod
+		return pod, nil
+	case err != nil && !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("failed to get pod %q: %w", name, err)
+	case f.pod == nil && err != nil:
+		// The requested pod has to exist, replacements are only looked for once it was forwarded to
+		return nil, fmt.Errorf("failed to get pod %q: %w", name, err)
+	case f.pod == nil:
+		return nil, fmt.Errorf("pod %q is not running (%s)", name, pod.Status.Phase)
+	}
+
+	selector, err := f.replacementSelector(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := f.readyPod(ctx, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replacements of pod %q: %w", name, err)
+	}
+	if replacement == nil && metav1.GetControllerOf(f.pod) == nil {
+		// Nothing recreates a pod without a controller
+		return nil, fmt.Errorf("pod %q is gone and no pod with its labels runs: %w", name, errPortForwardEnded)
+	}
+	if replacement == nil {
+		return nil, fmt.Errorf("pod %q is gone and no replacement is ready yet", name)
+	}
+	log.Printf("Port forward %s switches from pod %s to its replacement %s", f.session.ID, name, replacement.Name)
+	f.pod = replacement
+	return replacement, nil
+}
+
+// replacementSelector selects the pods that can replace the forwarded pod: the pods of its
+// controller, following a ReplicaSet up to its Deployment, whose rollouts replace the
+// ReplicaSet. Pods without a controller, or with one without a pod selector, are
+// replaced by pods with the same labels.
+func (f *portForward) replacementSelector(ctx context.Context, namespace string) (labels.Selector, error) {
+	if owner := metav1.GetControllerOf(f.pod); owner != nil {
+		controller, err := f.controller(ctx, namespace, *owner)
+		if err != nil {
+			return nil, err
+		}
+		if controller.GetKind() == "ReplicaSet" {
+			if owner := controllerOf(controller); owner != nil && owner.Kind == "Deployment" {
+				if controller, err = f.controller(ctx, namespace, *owner); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if selector, err := workloadPodSelector(controller); err == nil {
+			return selector, nil
+		}
+	}
+
+	podLabels := labels.Set{}
+	for key, value := range f.pod.Labels {
+		if !slices.Contains(podInstanceLabels, key) {
+			podLabels[key] = value
+		}
+	}
+	if len(podLabels) == 0 {
+		return nil, fmt.Errorf("pod %q is gone and has neither a controller nor labels to find its replacement: %w", f.pod.Name, errPortForwardEnded)
+	}
+	return labels.SelectorFromSet(podLabels), nil
+}
+
+// controller gets the controller of the forwarded pod, or of its ReplicaSet.
+func (f *portForward) controller(ctx context.Context, namespace string, owner metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion %q of %s %q: %w", owner.APIVersion, owner.Kind, owner.Name, err)
+	}
+	mapping, err := f.clients.discovery.restMapping(gv.WithKind(owner.Kind))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resource for %s: %w", owner.Kind, err)
+	}
+	obj, err := f.clients.DynamicClient.Resource(mapping.Resource).Namespace(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("pod %q is gone and so is its %s %q: %w", f.pod.Name, owner.Kind, owner.Name, errPortForwardEnded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %q: %w", owner.Kind, owner.Name, err)
+	}
+	return obj, nil
+}
+
+// controllerOf returns the owner reference of the object's controller, nil without one.
+func controllerOf(obj *unstructured.Unstructured) *metav1.OwnerReference {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Controller != nil && *owner.Controller {
+			return &owner
+		}
+	}
+	return nil
+}
+
+// readyPod returns the longest running ready pod of the selector, nil if none is ready.
+func (f *portForward) readyPod(ctx context.Context, namespace string, selector labels.Selector) (*corev1.Pod, error) {
+	list, err := f.clients.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*corev1.Pod
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && hasPodCondition(pod, corev1.PodReady) {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	// Prefer the longest running pod, it is the least likely to be replaced soon
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].CreationTimestamp.Before(&candidates[j].CreationTimestamp)
+	})
+	return candidates[0], nil
+}
+
+// serviceTargetPort maps a service port to the container port of the pod, resolving named target ports.
+func serviceTargetPort(service *unstructured.Unstructured, pod *corev1.Pod, servicePort int) (int, error) {
+	svc, err := fromUnstructured[corev1.Service](*service)
+	if err != nil {
+		return 0, err
+	}
+	for _, port := range svc.Spec.Ports {
+		if int(port.Port) != servicePort {
+			continue
+		}
+		switch {
+		case port.TargetPort.Type == intstr.String:
+			for _, c := range pod.Spec.Containers {
+				for _, containerPort := range c.Ports {
+					if containerPort.Name == port.TargetPort.StrVal {
+						return int(containerPort.ContainerPort), nil
+					}
+				}
+			}
+			return 0, fmt.Errorf("pod %q has no container port named %q", pod.Name, port.TargetPort.StrVal)
+		case port.TargetPort.IntValue() > 0:
+			return port.TargetPort.IntValue(), nil
+		default:
+			return servicePort, nil
+		}
+	}
+	return 0, fmt.Errorf("service %q has no port %d", svc.Name, servicePort)
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// defaultRolloutTimeout is how long a rollout is tracked, unless the caller sets a timeout.
+	defaultRolloutTimeout = 10 * time.Minute
+	// rolloutPodCheckInterval re-checks pods between workload changes, e.g. for crash loops.
+	rolloutPodCheckInterval = 5 * time.Second
+	// rolloutSyncTimeout is how long WatchRollout waits for the initial status.
+	rolloutSyncTimeout = 30 * time.Second
+	// maxFailingPods caps the failing pods reported per event.
+	maxFailingPods = 10
+)
+
+// Rollout phases.
+const (
+	rolloutProgressing = "progressing"
+	rolloutComplete    = "complete"
+	rolloutFailed      = "failed"
+	rolloutTimeout     = "timeout"
+)
+
+// RolloutStatus is emitted while a rollout is tracked. Message is what kubectl rollout status
+// prints, e.g. `Waiting for deployment "web" rollout to finish: 2 of 5 updated replicas are available...`.
+// The last event has a phase other than "progressing".
+type RolloutStatus struct {
+	Phase       string       `json:"phase"`
+	Message     string       `json:"message"`
+	Ready       string       `json:"ready,omitempty"` // Format like "2/5"
+	Desired     int32        `json:"desired"`
+	Updated     int32        `json:"updated"`
+	Available   int32        `json:"available"`
+	FailingPods []FailingPod `json:"failingPods,omitempty"`
+}
+
+// FailingPod is a pod of the workload that doesn't get ready.
+type FailingPod struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Restarts string `json:"restarts"`
+	Message  string `json:"message,omitempty"`
+}
+
+// RolloutWatch identifies a tracked rollout and the event its status is emitted as.
+// Status is the status when tracking started; events are only emitted while it is "progressing".
+type RolloutWatch struct {
+	ID     string        `json:"id"`
+	Event  string        `json:"event"`
+	Status RolloutStatus `json:"status"`
+}
+
+// WatchRollout tracks the rollout of a Deployment, StatefulSet or DaemonSet, e.g. after a
+// scale, restart or apply. It returns the current status and emits RolloutStatus events until
+// the rollout completes, fails with ProgressDeadlineExceeded or times out. A timeout of 0 uses
+// the default of 10 minutes.
+func (a *App) WatchRollout(clusterName, namespace, resourceName, name string, timeoutSeconds int) (*RolloutWatch, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	resourceInfo, gvr, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := rolloutKind(resourceInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultRolloutTimeout
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	id := a.rollouts.start(cancel)
+	watch := &RolloutWatch{ID: id, Event: "rollout:" + id}
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(clients.StreamDynamicClient, gvr, namespace, 0, cache.Indexers{}, func(opts *metav1.ListOptions) {
+		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}).Informer()
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(_, _ interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}); err != nil {
+		a.rollouts.stop(id)
+		return nil, fmt.Errorf("failed to watch %s %q: %w", resourceName, name, err)
+	}
+
+	go informer.Run(ctx.Done())
+	syncCtx, syncCancel := context.WithTimeout(ctx, rolloutSyncTimeout)
+	defer syncCancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		a.rollouts.stop(id)
+		return nil, fmt.Errorf("failed to watch %s %q: not synced within %s", resourceName, name, rolloutSyncTimeout)
+	}
+
+	current := func() RolloutStatus {
+		obj, exists, err := informer.GetStore().GetByKey(cache.NewObjectName(namespace, name).String())
+		if err != nil || !exists {
+			return RolloutStatus{Phase: rolloutFailed, Message: fmt.Sprintf("%s %q not found", kind, name)}
+		}
+		status, selector, err := rolloutStatus(kind, obj.(*unstructured.Unstructured))
+		if err != nil {
+			return RolloutStatus{Phase: rolloutFailed, Message: err.Error()}
+		}
+		if status.Phase != rolloutComplete {
+			status.FailingPods = failingPods(ctx, clients, namespace, selector)
+		}
+		return status
+	}
+
+	// Events only follow the initial status, a rollout that already ended has none
+	watch.Status = current()
+	if watch.Status.Phase != rolloutProgressing {
+		a.rollouts.stop(id)
+		return watch, nil
+	}
+
+	go func() {
+		defer a.rollouts.stop(id)
+
+		emit := func(status RolloutStatus) {
+			if a.ctx != nil {
+				wailsruntime.EventsEmit(a.ctx, watch.Event, status)
+			}
+		}
+
+		ticker := time.NewTicker(rolloutPodCheckInterval)
+		defer ticker.Stop()
+		last := watch.Status
+		for {
+			select {
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					last.Phase = rolloutTimeout
+					last.Message = fmt.Sprintf("timed out waiting for the rollout of %s %q after %s", kind, name, timeout)
+					emit(last)
+				}
+				return
+			case <-changed:
+			case <-ticker.C:
+			}
+
+			status := current()
+			if !reflect.DeepEqual(status, last) {
+				emit(status)
+				last = status
+			}
+			if status.Phase != rolloutProgressing {
+				log.Printf("Rollout of %s %q in %s/%s ended: %s", kind, name, clusterName, namespace, status.Message)
+				return
+			}
+		}
+	}()
+
+	return watch, nil
+}
+
+// StopRolloutWatch stops tracking a rollout.
+func (a *App) StopRolloutWatch(watchID string) {
+	a.rollouts.stop(watchID)
+}
+
+// rolloutStatus reproduces kubectl rollout status for the workload and returns its pod selector.
+func rolloutStatus(kind string, obj *unstructured.Unstructured) (RolloutStatus, *metav1.LabelSelector, error) {
+	switch kind {
+	case "Deployment":
+		var d appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &d); err != nil {
+			return RolloutStatus{}, nil, err
+		}
+		return deploymentRolloutStatus(&d), d.Spec.Selector, nil
+	case "StatefulSet":
+		var sts appsv1.StatefulSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &sts); err != nil {
+			return RolloutStatus{}, nil, err
+		}
+		return statefulSetRolloutStatus(&sts), sts.Spec.Selector, nil
+	default:
+		var ds appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds); err != nil {
+			return RolloutStatus{}, nil, err
+		}
+		return daemonSetRolloutStatus(&ds), ds.Spec.Selector, nil
+	}
+}
+
+func deploymentRolloutStatus(d *appsv1.Deployment) RolloutStatus {
+	ready, updated, available := summarizeDeployment(d)
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	status := RolloutStatus{Phase: rolloutProgressing, Ready: ready, Desired: desired, Updated: updated, Available: available}
+
+	if d.Generation > d.Status.ObservedGeneration {
+		status.Message = "Waiting for deployment spec update to be observed..."
+		return status
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			status.Phase = rolloutFailed
+			status.Message = fmt.Sprintf("deployment %q exceeded its progress deadline", d.Name)
+			return status
+		}
+	}
+	switch {
+	case d.Status.UpdatedReplicas < desired:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...", d.Name, d.Status.UpdatedReplicas, desired)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...", d.Name, d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...", d.Name, d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	default:
+		status.Phase = rolloutComplete
+		status.Message = fmt.Sprintf("deployment %q successfully rolled out", d.Name)
+	}
+	return status
+}
+
+func statefulSetRolloutStatus(sts *appsv1.StatefulSet) RolloutStatus {
+	desired := int32(1)
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+	status := RolloutStatus{
+		Phase:     rolloutProgressing,
+		Ready:     fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, desired),
+		Desired:   desired,
+		Updated:   sts.Status.UpdatedReplicas,
+		Available: sts.Status.AvailableReplicas,
+	}
+
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		status.Phase = rolloutComplete
+		status.Message = fmt.Sprintf("statefulset %q uses the %s update strategy, pods are updated when deleted", sts.Name, sts.Spec.UpdateStrategy.Type)
+		return status
+	}
+	if sts.Status.ObservedGeneration == 0 || sts.Generation > sts.Status.ObservedGeneration {
+		status.Message = "Waiting for statefulset spec update to be observed..."
+		return status
+	}
+	if sts.Status.ReadyReplicas < desired {
+		status.Message = fmt.Sprintf("Waiting for %d pods to be ready...", desired-sts.Status.ReadyReplicas)
+		return status
+	}
+	if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		if sts.Status.UpdatedReplicas < desired-*rollingUpdate.Partition {
+			status.Message = fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...", sts.Status.UpdatedReplicas, desired-*rollingUpdate.Partition)
+			return status
+		}
+		status.Phase = rolloutComplete
+		status.Message = fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", sts.Status.UpdatedReplicas)
+		return status
+	}
+	if sts.Status.UpdateRevision != sts.Status.CurrentRevision {
+		status.Message = fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...", sts.Status.UpdatedReplicas, sts.Status.UpdateRevision)
+		return status
+	}
+	status.Phase = rolloutComplete
+	status.Message = fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...", sts.Status.CurrentReplicas, sts.Status.CurrentRevision)
+	return status
+}
+
+func daemonSetRolloutStatus(ds *appsv1.DaemonSet) RolloutStatus {
+	desired := ds.Status.DesiredNumberScheduled
+	status := RolloutStatus{
+		Phase:     rolloutProgressing,
+		Ready:     fmt.Sprintf("%d/%d", ds.Status.NumberReady, desired),
+		Desired:   desired,
+		Updated:   ds.Status.UpdatedNumberScheduled,
+		Available: ds.Status.NumberAvailable,
+	}
+
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		status.Phase = rolloutComplete
+		status.Message = fmt.Sprintf("daemon set %q uses the %s update strategy, pods are updated when deleted", ds.Name, ds.Spec.UpdateStrategy.Type)
+		return status
+	}
+	if ds.Generation > ds.Status.ObservedGeneration {
+		status.Message = "Waiting for daemon set spec update to be observed..."
+		return status
+	}
+	switch {
+	case ds.Status.UpdatedNumberScheduled < desired:
+		status.Message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...", ds.Name, ds.Status.UpdatedNumberScheduled, desired)
+	case ds.Status.NumberAvailable < desired:
+		status.Message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...", ds.Name, ds.Status.NumberAvailable, desired)
+	default:
+		status.Phase = rolloutComplete
+		status.Message = fmt.Sprintf("daemon set %q successfully rolled out", ds.Name)
+	}
+	return status
+}
+
+// healthyPodStatuses are pod statuses of pods that are fine or still starting.
+var healthyPodStatuses = map[string]bool{
+	"Running":           true,
+	"Completed":         true,
+	"Succeeded":         true,
+	"Pending":           true,
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+	"Terminating":       true,
+}
+
+// failingPods returns the pods of the selector that are in an error state,
+// or running but not ready after restarting.
+func failingPods(ctx context.Context, clients *KubeClients, namespace string, labelSelector *metav1.LabelSelector) []FailingPod {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil
+	}
+	pods, err := clients.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		log.Printf("Failed to list pods of rollout: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	var failing []FailingPod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		summary := summarizePod(pod, now)
+		// Init progress like "Init:1/3" is fine, errors like "Init:CrashLoopBackOff" are not
+		healthy := healthyPodStatuses[summary.status] || (strings.HasPrefix(summary.status, "Init:") && strings.Contains(summary.status, "/"))
+		// A crash looping container is running between its restarts
+		if summary.status == "Running" && summary.restarts > 0 && !hasPodCondition(pod, corev1.PodReady) {
+			healthy = false
+		}
+		if healthy {
+			continue
+		}
+		failing = append(failing, FailingPod{
+			Name:     pod.Name,
+			Status:   summary.status,
+			Restarts: summary.restartsText,
+			Message:  podProblem(pod),
+		})
+		if len(failing) == maxFailingPods {
+			break
+		}
+	}
+	return failing
+}
+
+// podProblem returns the message of the first container that is waiting or terminated with an error.
+func podProblem(pod *corev1.Pod) string {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && waiting.Message != "" {
+			return fmt.Sprintf("%s: %s", status.Name, waiting.Message)
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return fmt.Sprintf("%s: last terminated with %s", status.Name, terminatedReason(terminated))
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue && condition.Message != "" {
+			return condition.Message
+		}
+	}
+	return pod.Status.Message
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/tools/pager"
+)
+
+const (
+	// searchConcurrency bounds how many clusters are searched at the same time.
+	searchConcurrency = 8
+	// defaultSearchTimeout is the time a cluster gets to answer, unless the request sets one.
+	defaultSearchTimeout = 20 * time.Second
+	maxSearchTimeout     = 2 * time.Minute
+	// maxSearchMatches caps the matches of one resource kind in one cluster.
+	maxSearchMatches = 1000
+)
+
+// Search event types.
+const (
+	searchMatches     = "matches"
+	searchError       = "error"
+	searchUnreachable = "unreachable"
+	searchDone        = "done"
+)
+
+// ClusterSearchRequest searches resources of several clusters. Every filter that is set
+// has to match: Name and Image are case-insensitive substrings, LabelSelector is applied
+// on the server. Image matches containers of pods and of pod templates.
+// An empty namespace or AllNamespaces searches the whole cluster.
+type ClusterSearchRequest struct {
+	Clusters       []string `json:"clusters"`
+	Resources      []string `json:"resources"`
+	Namespace      string   `json:"namespace,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	LabelSelector  string   `json:"labelSelector,omitempty"`
+	Image          string   `json:"image,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"`
+}
+
+// SearchMatch is a resource found by a search.
+type SearchMatch struct {
+	Cluster   string   `json:"cluster"`
+	Resource  string   `json:"resource"`
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Images    []string `json:"images,omitempty"`
+}
+
+// SearchEvent is emitted while a search runs. "matches" carries the matches of one
+// resource kind of one cluster, "error" a kind that couldn't be searched, "unreachable"
+// a cluster that didn't answer in time. The last event is "done", listing the unreachable clusters.
+type SearchEvent struct {
+	Type        string        `json:"type"`
+	Cluster     string        `json:"cluster,omitempty"`
+	Resource    string        `json:"resource,omitempty"`
+	Matches     []SearchMatch `json:"matches,omitempty"`
+	Truncated   bool          `json:"truncated,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Unreachable []string      `json:"unreachable,omitempty"`
+}
+
+// ClusterSearch identifies a running search and the event its results are emitted as.
+type ClusterSearch struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+}
+
+// SearchClusters starts searching the clusters in parallel and returns immediately.
+// Results are emitted as SearchEvents while clusters answer, until a "done" event.
+func (a *App) SearchClusters(request ClusterSearchRequest) (*ClusterSearch, error) {
+	if len(request.Clusters) == 0 {
+		return nil, fmt.Errorf("no clusters to search")
+	}
+	if len(request.Resources) == 0 {
+		return nil, fmt.Errorf("no resource kinds to search")
+	}
+	if request.Name == "" && request.LabelSelector == "" && request.Image == "" {
+		return nil, fmt.Errorf("a name, label selector or image is required")
+	}
+	if err := (ResourceSelectors{LabelSelector: request.LabelSelector}).validate(); err != nil {
+		return nil, err
+	}
+
+	timeout := defaultSearchTimeout
+	if request.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(request.TimeoutSeconds)*time.Second, maxSearchTimeout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id := a.searches.start(cancel)
+	search := &ClusterSearch{ID: id, Event: "search:" + id}
+
+	go func() {
+		defer a.searches.stop(id)
+		emit := func(event SearchEvent) {
+			if a.ctx != nil && ctx.Err() == nil {
+				wailsruntime.EventsEmit(a.ctx, search.Event, event)
+			}
+		}
+
+		var mu sync.Mutex
+		var unreachable []string
+		semaphore := make(chan struct{}, searchConcurrency)
+		var wg sync.WaitGroup
+		for _, clusterName := range request.Clusters {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				select {
+				case semaphore <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				defer func() { <-semaphore }()
+
+				clusterCtx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				if err := a.searchCluster(clusterCtx, clusterName, request, emit); err != nil {
+					log.Printf("Search of %s failed: %v", clusterName, err)
+					mu.Lock()
+					unreachable = append(unreachable, clusterName)
+					mu.Unlock()
+					emit(SearchEvent{Type: searchUnreachable, Cluster: clusterName, Error: err.Error()})
+				}
+			}()
+		}
+		wg.Wait()
+		emit(SearchEvent{Type: searchDone, Unreachable: unreachable})
+	}()
+
+	return search, nil
+}
+
+// CancelSearch stops a running search. No further events are emitted for it.
+func (a *App) CancelSearch(searchID string) {
+	a.searches.stop(searchID)
+}
+
+// searchCluster searches the resource kinds of one cluster one after another and emits
+// their matches. It returns an error only if the cluster is unreachable; failures of
+// single kinds, e.g. unknown or forbidden ones, are emitted as error events.
+func (a *App) searchCluster(ctx context.Context, clusterName string, request ClusterSearchRequest, emit func(SearchEvent)) error {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return err
+	}
+
+	for _, resourceName := range request.Resources {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out: %w", context.Cause(ctx))
+		}
+
+		matches, truncated, err := a.searchResource(ctx, clusterName, clients, resourceName, request)
+		if err != nil {
+			if isUnreachable(ctx, err) {
+				return err
+			}
+			emit(SearchEvent{Type: searchError, Cluster: clusterName, Resource: resourceName, Error: err.Error()})
+			continue
+		}
+		emit(SearchEvent{Type: searchMatches, Cluster: clusterName, Resource: resourceName, Matches: matches, Truncated: truncated})
+	}
+	return nil
+}
+
+func (a *App) searchResource(ctx context.Context, clusterName string, clients *KubeClients, resourceName string, request ClusterSearchRequest) ([]SearchMatch, bool, error) {
+	// Discovery doesn't take a context, so give up waiting for it when the cluster times out
+	type resolved struct {
+		info ResourceInfo
+		err  error
+	}
+	done := make(chan resolved, 1)
+	go func() {
+		info, _, err := a.findResourceInfo(clusterName, resourceName)
+		done <- resolved{info, err}
+	}()
+	var resourceInfo ResourceInfo
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, false, r.err
+		}
+		resourceInfo = r.info
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+
+	namespace := listNamespace(request.Namespace)
+	resourceClient := resourceInterface(clients.DynamicClient, resourceInfo.GroupVersionResource(), resourceInfo.Namespaced, namespace)
+	listPager := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return resourceClient.List(ctx, opts)
+	})
+	listPager.PageSize = listPageSize
+
+	name := strings.ToLower(request.Name)
+	image := strings.ToLower(request.Image)
+	matches := []SearchMatch{}
+	truncated := false
+	err := listPager.EachListItem(ctx, metav1.ListOptions{LabelSelector: request.LabelSelector}, func(obj runtime.Object) error {
+		item, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil
+		}
+		if name != "" && !strings.Contains(strings.ToLower(item.GetName()), name) {
+			return nil
+		}
+		images := podTemplateImages(item.Object)
+		if image != "" && !containsSubstring(images, image) {
+			return nil
+		}
+		if len(matches) == maxSearchMatches {
+			truncated = true
+			return errStopSearch
+		}
+		matches = append(matches, SearchMatch{
+			Cluster:   clusterName,
+			Resource:  resourceInfo.Name,
+			Kind:      resourceInfo.Kind,
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+			Images:    images,
+		})
+		return nil
+	})
+	if err != nil && err != errStopSearch {
+		return nil, false, err
+	}
+	return matches, truncated, nil
+}
+
+// errStopSearch ends a list early once enough matches were found.
+var errStopSearch = stderrors.New("enough matches")
+
+// isUnreachable tells a cluster that can't be reached from a request it rejected.
+func isUnreachable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.IsTimeout(err) || errors.IsServerTimeout(err) || errors.IsServiceUnavailable(err) {
+		return true
+	}
+	// Any other answer of the API server means it is reachable
+	var status errors.APIStatus
+	if stderrors.As(err, &status) {
+		return false
+	}
+	return utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) || utilnet.IsTimeout(err)
+}
+
+// podTemplateImages returns the container images of a pod or of the pod template
+// of a workload, e.g. a Deployment or a CronJob.
+func podTemplateImages(obj map[string]interface{}) []string {
+	podSpecPaths := [][]string{
+		{"spec"},
+		{"spec", "template", "spec"},
+		{"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+	var images []string
+	for _, path := range podSpecPaths {
+		spec, found, err := unstructured.NestedMap(obj, path...)
+		if !found || err != nil {
+			continue
+		}
+		for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			for _, c := range extractSlice(spec, field) {
+				if container, ok := c.(map[string]interface{}); ok {
+					if image := extractString(container, "image"); image != "" {
+						images = append(images, image)
+					}
+				}
+			}
+		}
+		if len(images) > 0 {
+			break
+		}
+	}
+	return images
+}
+
+func containsSubstring(values []string, substring string) bool {
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ResourceSelectors filters resource lists on the server,
+// e.g. "app=web,tier in (frontend)" and "status.phase!=Running".
+type ResourceSelectors struct {
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+// SelectorError points at the part of a selector that failed to parse.
+// Position is the byte offset of the offending requirement.
+type SelectorError struct {
+	Selector string `json:"selector"` // "label" or "field"
+	Position int    `json:"position"`
+	Message  string `json:"message"`
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid %s selector at position %d: %s", e.Selector, e.Position, e.Message)
+}
+
+// validate parses both selectors and returns the first syntax error.
+func (s ResourceSelectors) validate() error {
+	if err := validateSelector("label", s.LabelSelector, labelRequirements, func(term string) error {
+		_, err := labels.Parse(term)
+		return err
+	}); err != nil {
+		return err
+	}
+	return validateSelector("field", s.FieldSelector, fieldRequirements, func(term string) error {
+		_, err := fields.ParseSelector(term)
+		return err
+	})
+}
+
+// key identifies the selectors, e.g. in watch keys.
+func (s ResourceSelectors) key() string {
+	return s.LabelSelector + "|" + s.FieldSelector
+}
+
+func (s ResourceSelectors) applyTo(opts *metav1.ListOptions) {
+	opts.LabelSelector = s.LabelSelector
+	opts.FieldSelector = s.FieldSelector
+}
+
+// validateSelector parses the selector requirement by requirement so that
+// an error can be reported with the position of the requirement it is in.
+func validateSelector(kind, selector string, split func(string) []int, parse func(string) error) error {
+	if strings.TrimSpace(selector) == "" {
+		return nil
+	}
+
+	starts := split(selector)
+	for i, start := range starts {
+		end := len(selector)
+		if i+1 < len(starts) {
+			end = starts[i+1] - 1
+		}
+		term := selector[start:end]
+		// The parsers skip empty terms, which the API server rejects
+		if strings.TrimSpace(term) == "" {
+			return &SelectorError{Selector: kind, Position: start, Message: "empty requirement"}
+		}
+		if err := parse(term); err != nil {
+			position := start + len(term) - len(strings.TrimLeft(term, " \t"))
+			return &SelectorError{Selector: kind, Position: position, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// labelRequirements returns the start offsets of the comma separated requirements
+// of a label selector. Commas inside "in (a,b)" sets don't separate requirements.
+func labelRequirements(selector string) []int {
+	starts := []int{0}
+	depth := 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				starts = append(starts, i+1)
+			}
+		}
+	}
+	return starts
+}
+
+// fieldRequirements returns the start offsets of the requirements of a field selector,
+// where a comma escaped with a backslash is part of the value.
+func fieldRequirements(selector string) []int {
+	starts := []int{0}
+	escaped := false
+	for i, c := range selector {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ',':
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// ValidateSelectors checks the syntax of the selectors and returns the errors found, if any.
+func (a *App) ValidateSelectors(selectors ResourceSelectors) []SelectorError {
+	var selectorErrors []SelectorError
+	for _, s := range []ResourceSelectors{{LabelSelector: selectors.LabelSelector}, {FieldSelector: selectors.FieldSelector}} {
+		if err, ok := s.validate().(*SelectorError); ok {
+			selectorErrors = append(selectorErrors, *err)
+		}
+	}
+	return selectorErrors
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResourceSelectorsValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors ResourceSelectors
+		want      *SelectorError // nil when the selectors are valid
+	}{
+		{
+			name:      "empty",
+			selectors: ResourceSelectors{LabelSelector: " ", FieldSelector: ""},
+		},
+		{
+			name: "valid",
+			selectors: ResourceSelectors{
+				LabelSelector: "app=web, tier in (frontend,backend),!canary",
+				FieldSelector: `status.phase!=Running,metadata.name=a\,b`,
+			},
+		},
+		{
+			name:      "bad label operator",
+			selectors: ResourceSelectors{LabelSelector: "app=web,tier=~front"},
+			want:      &SelectorError{Selector: "label", Position: 8},
+		},
+		{
+			name:      "non-numeric greater than",
+			selectors: ResourceSelectors{LabelSelector: "app=web, replicas>many"},
+			want:      &SelectorError{Selector: "label", Position: 9},
+		},
+		{
+			name:      "unknown set operator",
+			selectors: ResourceSelectors{LabelSelector: "tier within (a,b)"},
+			want:      &SelectorError{Selector: "label", Position: 0},
+		},
+		{
+			name:      "unclosed set",
+			selectors: ResourceSelectors{LabelSelector: "app=web,  tier in (a,b"},
+			want:      &SelectorError{Selector: "label", Position: 10},
+		},
+		{
+			name:      "unopened set",
+			selectors: ResourceSelectors{LabelSelector: "app=web,tier in a,b)"},
+			want:      &SelectorError{Selector: "label", Position: 8},
+		},
+		{
+			name:      "empty label requirement",
+			selectors: ResourceSelectors{LabelSelector: "app=web,,tier=front"},
+			want:      &SelectorError{Selector: "label", Position: 8, Message: "empty requirement"},
+		},
+		{
+			name:      "field without operator",
+			selectors: ResourceSelectors{FieldSelector: "status.phase=Running,spec.nodeName"},
+			want:      &SelectorError{Selector: "field", Position: 21},
+		},
+		{
+			name:      "unsupported field operator",
+			selectors: ResourceSelectors{FieldSelector: "metadata.namespace=default, spec.replicas>1"},
+			want:      &SelectorError{Selector: "field", Position: 28},
+		},
+		{
+			name:      "escaped comma doesn't split a field requirement",
+			selectors: ResourceSelectors{FieldSelector: `metadata.name=a\,b, spec.nodeName`},
+			want:      &SelectorError{Selector: "field", Position: 20},
+		},
+		{
+			name:      "label errors are reported first",
+			selectors: ResourceSelectors{LabelSelector: "tier in (a", FieldSelector: "spec.nodeName"},
+			want:      &SelectorError{Selector: "label", Position: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.selectors.validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			got, ok := err.(*SelectorError)
+			if !ok {
+				t.Fatalf("validate() = %v, want a *SelectorError", err)
+			}
+			if got.Selector != tt.want.Selector || got.Position != tt.want.Position {
+				t.Errorf("validate() = %s selector at %d, want %s selector at %d (%s)", got.Selector, got.Position, tt.want.Selector, tt.want.Position, got.Message)
+			}
+			if tt.want.Message != "" && got.Message != tt.want.Message {
+				t.Errorf("message = %q, want %q", got.Message, tt.want.Message)
+			}
+			if got.Message == "" {
+				t.Error("message is empty")
+			}
+		})
+	}
+}
+
+func TestValidateSelectors(t *testing.T) {
+	a := &App{}
+
+	if got := a.ValidateSelectors(ResourceSelectors{LabelSelector: "app=web", FieldSelector: "spec.nodeName=node-1"}); got != nil {
+		t.Errorf("ValidateSelectors() = %v, want none", got)
+	}
+
+	got := a.ValidateSelectors(ResourceSelectors{LabelSelector: "app=web,tier in (a", FieldSelector: "status.phase=Running,,"})
+	for i := range got {
+		got[i].Message = ""
+	}
+	want := []SelectorError{{Selector: "label", Position: 8}, {Selector: "field", Position: 21}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateSelectors() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// StatefulSetResponse extends ResourceResponse with StatefulSet-specific fields.
+type StatefulSetResponse struct {
+	ResourceResponse
+	Ready    string `json:"ready"`    // Format like "2/3"
+	Current  int32  `json:"current"`  // Replicas of the current revision
+	UpToDate int32  `json:"upToDate"` // Replicas of the update revision
+}
+
+// DaemonSetResponse extends ResourceResponse with DaemonSet-specific fields.
+type DaemonSetResponse struct {
+	ResourceResponse
+	Desired      int32 `json:"desired"`
+	Current      int32 `json:"current"`
+	Ready        int32 `json:"ready"`
+	UpToDate     int32 `json:"upToDate"`
+	Available    int32 `json:"available"`
+	Misscheduled int32 `json:"misscheduled"`
+}
+
+// JobResponse extends ResourceResponse with Job-specific fields.
+type JobResponse struct {
+	ResourceResponse
+	Status      string `json:"status"`      // Running, Complete, Failed or Suspended
+	Completions string `json:"completions"` // Format like "1/3"
+	Duration    string `json:"duration"`
+}
+
+// CronJobResponse extends ResourceResponse with CronJob-specific fields.
+type CronJobResponse struct {
+	ResourceResponse
+	Schedule     string `json:"schedule"`
+	Suspend      bool   `json:"suspend"`
+	Active       int    `json:"active"`
+	LastSchedule string `json:"lastSchedule"` // Time since the last schedule, like the age
+}
+
+// ServiceResponse extends ResourceResponse with Service-specific fields.
+type ServiceResponse struct {
+	ResourceResponse
+	Type       string `json:"type"`
+	ClusterIP  string `json:"clusterIP"`
+	ExternalIP string `json:"externalIP"`
+	Ports      string `json:"ports"` // Format like "80/TCP,443:30443/TCP"
+}
+
+// IngressResponse extends ResourceResponse with Ingress-specific fields.
+type IngressResponse struct {
+	ResourceResponse
+	Class   string `json:"class"`
+	Hosts   string `json:"hosts"`
+	Address string `json:"address"`
+	Ports   string `json:"ports"`
+}
+
+// NodeResponse extends ResourceResponse with Node-specific fields.
+type NodeResponse struct {
+	ResourceResponse
+	Status     string   `json:"status"` // Format like "Ready,SchedulingDisabled"
+	Roles      string   `json:"roles"`
+	Version    string   `json:"version"`
+	Conditions []string `json:"conditions"` // Pressure and other abnormal conditions that are true
+	Taints     []string `json:"taints"`
+}
+
+// PersistentVolumeClaimResponse extends ResourceResponse with PVC-specific fields.
+type PersistentVolumeClaimResponse struct {
+	ResourceResponse
+	Status       string `json:"status"`
+	Volume       string `json:"volume"`
+	Capacity     string `json:"capacity"`
+	AccessModes  string `json:"accessModes"` // Format like "RWO,ROX"
+	StorageClass string `json:"storageClass"`
+}
+
+// fromUnstructured converts a listed item into its typed object.
+func fromUnstructured[T any](u unstructured.Unstructured) (*T, error) {
+	var obj T
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &obj)
+	return &obj, err
+}
+
+// summarizeKind builds the typed response for kinds with a summary, ok is false for other kinds.
+// The group is part of the match, so that custom resources which share a kind name with a
+// built-in one, like Knative Services, aren't converted into the built-in type.
+func summarizeKind(groupKind schema.GroupKind, base ResourceResponse, item unstructured.Unstructured) (response interface{}, ok bool, err error) {
+	switch groupKind {
+	case schema.GroupKind{Group: "apps", Kind: "StatefulSet"}:
+		s, err := fromUnstructured[appsv1.StatefulSet](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeStatefulSet(base, s), true, nil
+	case schema.GroupKind{Group: "apps", Kind: "DaemonSet"}:
+		d, err := fromUnstructured[appsv1.DaemonSet](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeDaemonSet(base, d), true, nil
+	case schema.GroupKind{Group: "batch", Kind: "Job"}:
+		j, err := fromUnstructured[batchv1.Job](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeJob(base, j, time.Now()), true, nil
+	case schema.GroupKind{Group: "batch", Kind: "CronJob"}:
+		c, err := fromUnstructured[batchv1.CronJob](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeCronJob(base, c, time.Now()), true, nil
+	case schema.GroupKind{Group: "", Kind: "Service"}:
+		s, err := fromUnstructured[corev1.Service](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeService(base, s), true, nil
+	case schema.GroupKind{Group: "networking.k8s.io", Kind: "Ingress"}:
+		i, err := fromUnstructured[networkingv1.Ingress](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeIngress(base, i), true, nil
+	case schema.GroupKind{Group: "", Kind: "Node"}:
+		n, err := fromUnstructured[corev1.Node](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizeNode(base, n), true, nil
+	case schema.GroupKind{Group: "", Kind: "PersistentVolumeClaim"}:
+		p, err := fromUnstructured[corev1.PersistentVolumeClaim](item)
+		if err != nil {
+			return nil, true, err
+		}
+		return summarizePersistentVolumeClaim(base, p), true, nil
+	}
+	return nil, false, nil
+}
+
+func summarizeStatefulSet(base ResourceResponse, s *appsv1.StatefulSet) StatefulSetResponse {
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	return StatefulSetResponse{
+		ResourceResponse: base,
+		Ready:            fmt.Sprintf("%d/%d", s.Status.ReadyReplicas, replicas),
+		Current:          s.Status.CurrentReplicas,
+		UpToDate:         s.Status.UpdatedReplicas,
+	}
+}
+
+func summarizeDaemonSet(base ResourceResponse, d *appsv1.DaemonSet) DaemonSetResponse {
+	return DaemonSetResponse{
+		ResourceResponse: base,
+		Desired:          d.Status.DesiredNumberScheduled,
+		Current:          d.Status.CurrentNumberScheduled,
+		Ready:            d.Status.NumberReady,
+		UpToDate:         d.Status.UpdatedNumberScheduled,
+		Available:        d.Status.NumberAvailable,
+		Misscheduled:     d.Status.NumberMisscheduled,
+	}
+}
+
+func summarizeJob(base ResourceResponse, j *batchv1.Job, now time.Time) JobResponse {
+	// Same as kubectl: without completions the job is done after one success
+	var completions string
+	switch {
+	case j.Spec.Completions != nil:
+		completions = fmt.Sprintf("%d/%d", j.Status.Succeeded, *j.Spec.Completions)
+	case j.Spec.Parallelism != nil && *j.Spec.Parallelism > 1:
+		completions = fmt.Sprintf("%d/1 of %d", j.Status.Succeeded, *j.Spec.Parallelism)
+	default:
+		completions = fmt.Sprintf("%d/1", j.Status.Succeeded)
+	}
+
+	var jobDuration string
+	if j.Status.StartTime != nil {
+		end := now
+		if j.Status.CompletionTime != nil {
+			end = j.Status.CompletionTime.Time
+		}
+		jobDuration = duration.HumanDuration(end.Sub(j.Status.StartTime.Time))
+	}
+
+	status := "Running"
+	for _, c := range j.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			status = "Complete"
+		case batchv1.JobFailed:
+			status = "Failed"
+		case batchv1.JobSuspended:
+			status = "Suspended"
+		}
+	}
+	if j.DeletionTimestamp != nil {
+		status = "Terminating"
+	}
+
+	return JobResponse{
+		ResourceResponse: base,
+		Status:           status,
+		Completions:      completions,
+		Duration:         jobDuration,
+	}
+}
+
+func summarizeCronJob(base ResourceResponse, c *batchv1.CronJob, now time.Time) CronJobResponse {
+	lastSchedule := "<none>"
+	if c.Status.LastScheduleTime != nil {
+		lastSchedule = duration.HumanDuration(now.Sub(c.Status.LastScheduleTime.Time))
+	}
+	schedule := c.Spec.Schedule
+	if c.Spec.TimeZone != nil {
+		schedule = fmt.Sprintf("%s (%s)", schedule, *c.Spec.TimeZone)
+	}
+	return CronJobResponse{
+		ResourceResponse: base,
+		Schedule:         schedule,
+		Suspend:          c.Spec.Suspend != nil && *c.Spec.Suspend,
+		Active:           len(c.Status.Active),
+		LastSchedule:     lastSchedule,
+	}
+}
+
+func summarizeService(base ResourceResponse, s *corev1.Service) ServiceResponse {
+	clusterIP := s.Spec.ClusterIP
+	if clusterIP == "" {
+		clusterIP = "<none>"
+	}
+
+	// External IPs as kubectl reports them for each service type
+	externalIPs := append([]string{}, s.Spec.ExternalIPs...)
+	switch s.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		ingressIPs := loadBalancerAddresses(s.Status.LoadBalancer.Ingress)
+		externalIPs = append(ingressIPs, externalIPs...)
+		if len(externalIPs) == 0 {
+			externalIPs = []string{"<pending>"}
+		}
+	case corev1.ServiceTypeExternalName:
+		externalIPs = []string{s.Spec.ExternalName}
+	}
+	externalIP := strings.Join(externalIPs, ",")
+	if externalIP == "" {
+		externalIP = "<none>"
+	}
+
+	var ports []string
+	for _, p := range s.Spec.Ports {
+		port := fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+		if p.NodePort > 0 {
+			port = fmt.Sprintf("%d:%d/%s", p.Port, p.NodePort, p.Protocol)
+		}
+		ports = append(ports, port)
+	}
+	portsText := strings.Join(ports, ",")
+	if portsText == "" {
+		portsText = "<none>"
+	}
+
+	return ServiceResponse{
+		ResourceResponse: base,
+		Type:             string(s.Spec.Type),
+		ClusterIP:        clusterIP,
+		ExternalIP:       externalIP,
+		Ports:            portsText,
+	}
+}
+
+func summarizeIngress(base ResourceResponse, i *networkingv1.Ingress) IngressResponse {
+	class := "<none>"
+	if i.Spec.IngressClassName != nil {
+		class = *i.Spec.IngressClassName
+	} else if annotation, ok := i.Annotations["kubernetes.io/ingress.class"]; ok {
+		class = annotation
+	}
+
+	var hosts []string
+	for _, rule := range i.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	hostsText := strings.Join(hosts, ",")
+	if hostsText == "" {
+		hostsText = "*"
+	}
+
+	var addresses []string
+	for _, ingress := range i.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		} else if ingress.Hostname != "" {
+			addresses = append(addresses, ingress.Hostname)
+		}
+	}
+
+	ports := "80"
+	if len(i.Spec.TLS) > 0 {
+		ports = "80, 443"
+	}
+
+	return IngressResponse{
+		ResourceResponse: base,
+		Class:            class,
+		Hosts:            hostsText,
+		Address:          strings.Join(addresses, ","),
+		Ports:            ports,
+	}
+}
+
+func loadBalancerAddresses(ingresses []corev1.LoadBalancerIngress) []string {
+	var addresses []string
+	for _, ingress := range ingresses {
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		} else if ingress.Hostname != "" {
+			addresses = append(addresses, ingress.Hostname)
+		}
+	}
+	return addresses
+}
+
+func summarizeNode(base ResourceResponse, n *corev1.Node) NodeResponse {
+	status := "Unknown"
+	var conditions []string
+	for _, c := range n.Status.Conditions {
+		switch {
+		case c.Type == corev1.NodeReady:
+			switch c.Status {
+			case corev1.ConditionTrue:
+				status = "Ready"
+			case corev1.ConditionFalse:
+				status = "NotReady"
+			}
+		case c.Status == corev1.ConditionTrue:
+			// Every other condition, like MemoryPressure, is only worth showing when true
+			conditions = append(conditions, string(c.Type))
+		}
+	}
+	if n.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+
+	var roles []string
+	for label, value := range n.Labels {
+		if role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/"); ok && role != "" {
+			roles = append(roles, role)
+		} else if label == "kubernetes.io/role" && value != "" {
+			roles = append(roles, value)
+		}
+	}
+	sort.Strings(roles)
+	rolesText := strings.Join(roles, ",")
+	if rolesText == "" {
+		rolesText = "<none>"
+	}
+
+	var taints []string
+	for _, t := range n.Spec.Taints {
+		taint := t.Key
+		if t.Value != "" {
+			taint += "=" + t.Value
+		}
+		taints = append(taints, taint+":"+string(t.Effect))
+	}
+
+	return NodeResponse{
+		ResourceResponse: base,
+		Status:           status,
+		Roles:            rolesText,
+		Version:          n.Status.NodeInfo.KubeletVersion,
+		Conditions:       conditions,
+		Taints:           taints,
+	}
+}
+
+var accessModeShortNames = map[corev1.PersistentVolumeAccessMode]string{
+	corev1.ReadWriteOnce:    "RWO",
+	corev1.ReadOnlyMany:     "ROX",
+	corev1.ReadWriteMany:    "RWX",
+	corev1.ReadWriteOncePod: "RWOP",
+}
+
+func summarizePersistentVolumeClaim(base ResourceResponse, p *corev1.PersistentVolumeClaim) PersistentVolumeClaimResponse {
+	status := string(p.Status.Phase)
+	if p.DeletionTimestamp != nil {
+		status = "Terminating"
+	}
+
+	var capacity string
+	if storage, ok := p.Status.Capacity[corev1.ResourceStorage]; ok {
+		capacity = storage.String()
+	}
+
+	var accessModes []string
+	for _, mode := range p.Status.AccessModes {
+		if short, ok := accessModeShortNames[mode]; ok {
+			accessModes = append(accessModes, short)
+		}
+	}
+
+	storageClass := ""
+	if p.Spec.StorageClassName != nil {
+		storageClass = *p.Spec.StorageClassName
+	} else if annotation, ok := p.Annotations[corev1.BetaStorageClassAnnotation]; ok {
+		storageClass = annotation
+	}
+
+	return PersistentVolumeClaimResponse{
+		ResourceResponse: base,
+		Status:           status,
+		Volume:           p.Spec.VolumeName,
+		Capacity:         capacity,
+		AccessModes:      strings.Join(accessModes, ","),
+		StorageClass:     storageClass,
+	}
+}
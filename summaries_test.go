@@ -0,0 +1,445 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var summaryNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSummarizeStatefulSet(t *testing.T) {
+	tests := []struct {
+		name string
+		set  appsv1.StatefulSet
+		want StatefulSetResponse
+	}{
+		{
+			name: "replicas default to one",
+			set:  appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 1, CurrentReplicas: 1, UpdatedReplicas: 1}},
+			want: StatefulSetResponse{Ready: "1/1", Current: 1, UpToDate: 1},
+		},
+		{
+			name: "rolling update",
+			set: appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: ptr(int32(3))},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2, CurrentReplicas: 2, UpdatedReplicas: 1},
+			},
+			want: StatefulSetResponse{Ready: "2/3", Current: 2, UpToDate: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeStatefulSet(ResourceResponse{}, &tt.set); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeStatefulSet() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeDaemonSet(t *testing.T) {
+	d := appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{
+		DesiredNumberScheduled: 5,
+		CurrentNumberScheduled: 4,
+		NumberReady:            3,
+		UpdatedNumberScheduled: 2,
+		NumberAvailable:        3,
+		NumberMisscheduled:     1,
+	}}
+	want := DaemonSetResponse{Desired: 5, Current: 4, Ready: 3, UpToDate: 2, Available: 3, Misscheduled: 1}
+	if got := summarizeDaemonSet(ResourceResponse{}, &d); !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeDaemonSet() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarizeJob(t *testing.T) {
+	start := metav1.NewTime(summaryNow.Add(-5 * time.Minute))
+	completion := metav1.NewTime(summaryNow.Add(-3 * time.Minute))
+
+	tests := []struct {
+		name string
+		job  batchv1.Job
+		want JobResponse
+	}{
+		{
+			name: "single completion, running",
+			job: batchv1.Job{
+				Status: batchv1.JobStatus{StartTime: &start},
+			},
+			want: JobResponse{Status: "Running", Completions: "0/1", Duration: "5m"},
+		},
+		{
+			name: "completions",
+			job: batchv1.Job{
+				Spec: batchv1.JobSpec{Completions: ptr(int32(3)), Parallelism: ptr(int32(2))},
+				Status: batchv1.JobStatus{
+					Succeeded:      3,
+					StartTime:      &start,
+					CompletionTime: &completion,
+					Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+				},
+			},
+			want: JobResponse{Status: "Complete", Completions: "3/3", Duration: "2m"},
+		},
+		{
+			name: "parallelism without completions",
+			job: batchv1.Job{
+				Spec:   batchv1.JobSpec{Parallelism: ptr(int32(4))},
+				Status: batchv1.JobStatus{Succeeded: 1},
+			},
+			want: JobResponse{Status: "Running", Completions: "1/1 of 4"},
+		},
+		{
+			name: "failed",
+			job: batchv1.Job{
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, Status: corev1.ConditionFalse},
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue},
+				}},
+			},
+			want: JobResponse{Status: "Failed", Completions: "0/1"},
+		},
+		{
+			name: "suspended",
+			job: batchv1.Job{
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobSuspended, Status: corev1.ConditionTrue}}},
+			},
+			want: JobResponse{Status: "Suspended", Completions: "0/1"},
+		},
+		{
+			name: "terminating",
+			job: batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &completion},
+			},
+			want: JobResponse{Status: "Terminating", Completions: "0/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeJob(ResourceResponse{}, &tt.job, summaryNow); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeJob() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeCronJob(t *testing.T) {
+	lastSchedule := metav1.NewTime(summaryNow.Add(-90 * time.Second))
+
+	tests := []struct {
+		name string
+		cron batchv1.CronJob
+		want CronJobResponse
+	}{
+		{
+			name: "never scheduled",
+			cron: batchv1.CronJob{Spec: batchv1.CronJobSpec{Schedule: "*/5 * * * *"}},
+			want: CronJobResponse{Schedule: "*/5 * * * *", LastSchedule: "<none>"},
+		},
+		{
+			name: "time zone, suspended and active",
+			cron: batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{Schedule: "0 3 * * *", TimeZone: ptr("Europe/Berlin"), Suspend: ptr(true)},
+				Status: batchv1.CronJobStatus{
+					Active:           []corev1.ObjectReference{{Name: "a"}, {Name: "b"}},
+					LastScheduleTime: &lastSchedule,
+				},
+			},
+			want: CronJobResponse{Schedule: "0 3 * * * (Europe/Berlin)", Suspend: true, Active: 2, LastSchedule: "90s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeCronJob(ResourceResponse{}, &tt.cron, summaryNow); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeCronJob() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeService(t *testing.T) {
+	tests := []struct {
+		name    string
+		service corev1.Service
+		want    ServiceResponse
+	}{
+		{
+			name: "cluster IP",
+			service: corev1.Service{Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: "10.0.0.1",
+				Ports: []corev1.ServicePort{
+					{Port: 80, Protocol: corev1.ProtocolTCP},
+					{Port: 53, Protocol: corev1.ProtocolUDP},
+				},
+			}},
+			want: ServiceResponse{Type: "ClusterIP", ClusterIP: "10.0.0.1", ExternalIP: "<none>", Ports: "80/TCP,53/UDP"},
+		},
+		{
+			name: "headless without ports",
+			service: corev1.Service{Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: corev1.ClusterIPNone,
+			}},
+			want: ServiceResponse{Type: "ClusterIP", ClusterIP: "None", ExternalIP: "<none>", Ports: "<none>"},
+		},
+		{
+			name: "node port",
+			service: corev1.Service{Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeNodePort,
+				ClusterIP: "10.0.0.2",
+				Ports:     []corev1.ServicePort{{Port: 443, NodePort: 30443, Protocol: corev1.ProtocolTCP}},
+			}},
+			want: ServiceResponse{Type: "NodePort", ClusterIP: "10.0.0.2", ExternalIP: "<none>", Ports: "443:30443/TCP"},
+		},
+		{
+			name: "load balancer pending",
+			service: corev1.Service{Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeLoadBalancer,
+				ClusterIP: "10.0.0.3",
+				Ports:     []corev1.ServicePort{{Port: 80, NodePort: 31080, Protocol: corev1.ProtocolTCP}},
+			}},
+			want: ServiceResponse{Type: "LoadBalancer", ClusterIP: "10.0.0.3", ExternalIP: "<pending>", Ports: "80:31080/TCP"},
+		},
+		{
+			name: "load balancer with ingress and external IPs",
+			service: corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:        corev1.ServiceTypeLoadBalancer,
+					ClusterIP:   "10.0.0.4",
+					ExternalIPs: []string{"192.0.2.10"},
+					Ports:       []corev1.ServicePort{{Port: 80, NodePort: 31081, Protocol: corev1.ProtocolTCP}},
+				},
+				Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+					{IP: "203.0.113.5"},
+					{Hostname: "lb.example.com"},
+				}}},
+			},
+			want: ServiceResponse{Type: "LoadBalancer", ClusterIP: "10.0.0.4", ExternalIP: "203.0.113.5,lb.example.com,192.0.2.10", Ports: "80:31081/TCP"},
+		},
+		{
+			name: "external name",
+			service: corev1.Service{Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: "db.example.com",
+			}},
+			want: ServiceResponse{Type: "ExternalName", ClusterIP: "<none>", ExternalIP: "db.example.com", Ports: "<none>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeService(ResourceResponse{}, &tt.service); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeService() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeIngress(t *testing.T) {
+	tests := []struct {
+		name    string
+		ingress networkingv1.Ingress
+		want    IngressResponse
+	}{
+		{
+			name:    "no class and no hosts",
+			ingress: networkingv1.Ingress{},
+			want:    IngressResponse{Class: "<none>", Hosts: "*", Ports: "80"},
+		},
+		{
+			name: "class annotation",
+			ingress: networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kubernetes.io/ingress.class": "nginx"}},
+				Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "a.example.com"}, {}}},
+			},
+			want: IngressResponse{Class: "nginx", Hosts: "a.example.com", Ports: "80"},
+		},
+		{
+			name: "class name, TLS and addresses",
+			ingress: networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kubernetes.io/ingress.class": "nginx"}},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: ptr("traefik"),
+					Rules:            []networkingv1.IngressRule{{Host: "a.example.com"}, {Host: "b.example.com"}},
+					TLS:              []networkingv1.IngressTLS{{Hosts: []string{"a.example.com"}}},
+				},
+				Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+					Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.7"}, {Hostname: "lb.example.com"}},
+				}},
+			},
+			want: IngressResponse{Class: "traefik", Hosts: "a.example.com,b.example.com", Address: "203.0.113.7,lb.example.com", Ports: "80, 443"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeIngress(ResourceResponse{}, &tt.ingress); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeIngress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeNode(t *testing.T) {
+	tests := []struct {
+		name string
+		node corev1.Node
+		want NodeResponse
+	}{
+		{
+			name: "no ready condition",
+			node: corev1.Node{},
+			want: NodeResponse{Status: "Unknown", Roles: "<none>"},
+		},
+		{
+			name: "ready control plane with taints",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					"node-role.kubernetes.io/control-plane": "",
+					"node-role.kubernetes.io/etcd":          "",
+					"node-role.kubernetes.io/":              "",
+					"kubernetes.io/hostname":                "cp-1",
+				}},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+					{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoExecute},
+				}},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+						{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					},
+					NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.1"},
+				},
+			},
+			want: NodeResponse{
+				Status:  "Ready",
+				Roles:   "control-plane,etcd",
+				Version: "v1.30.1",
+				Taints:  []string{"node-role.kubernetes.io/control-plane:NoSchedule", "dedicated=infra:NoExecute"},
+			},
+		},
+		{
+			name: "cordoned with pressure and legacy role label",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"kubernetes.io/role": "worker"}},
+				Spec:       corev1.NodeSpec{Unschedulable: true},
+				Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+					{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+				}},
+			},
+			want: NodeResponse{
+				Status:     "NotReady,SchedulingDisabled",
+				Roles:      "worker",
+				Conditions: []string{"DiskPressure"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeNode(ResourceResponse{}, &tt.node); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeNode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizePersistentVolumeClaim(t *testing.T) {
+	deleted := metav1.NewTime(summaryNow)
+
+	tests := []struct {
+		name string
+		pvc  corev1.PersistentVolumeClaim
+		want PersistentVolumeClaimResponse
+	}{
+		{
+			name: "pending",
+			pvc: corev1.PersistentVolumeClaim{
+				Spec:   corev1.PersistentVolumeClaimSpec{StorageClassName: ptr("standard")},
+				Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+			},
+			want: PersistentVolumeClaimResponse{Status: "Pending", StorageClass: "standard"},
+		},
+		{
+			name: "bound with access modes",
+			pvc: corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{corev1.BetaStorageClassAnnotation: "legacy"}},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Phase:       corev1.ClaimBound,
+					Capacity:    corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany, corev1.ReadWriteOncePod},
+				},
+			},
+			want: PersistentVolumeClaimResponse{Status: "Bound", Volume: "pv-1", Capacity: "10Gi", AccessModes: "RWO,ROX,RWX,RWOP", StorageClass: "legacy"},
+		},
+		{
+			name: "terminating",
+			pvc: corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+			},
+			want: PersistentVolumeClaimResponse{Status: "Terminating"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizePersistentVolumeClaim(ResourceResponse{}, &tt.pvc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizePersistentVolumeClaim() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeKind(t *testing.T) {
+	toItem := func(t *testing.T, obj runtime.Object) unstructured.Unstructured {
+		t.Helper()
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return unstructured.Unstructured{Object: content}
+	}
+	service := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "10.0.0.1"}}
+
+	tests := []struct {
+		name      string
+		groupKind schema.GroupKind
+		wantOK    bool
+		wantType  interface{}
+	}{
+		{name: "core service", groupKind: schema.GroupKind{Kind: "Service"}, wantOK: true, wantType: ServiceResponse{}},
+		{name: "knative service", groupKind: schema.GroupKind{Group: "serving.knative.dev", Kind: "Service"}},
+		{name: "custom ingress", groupKind: schema.GroupKind{Group: "example.com", Kind: "Ingress"}},
+		{name: "custom job", groupKind: schema.GroupKind{Group: "example.com", Kind: "Job"}},
+		{name: "unknown kind", groupKind: schema.GroupKind{Kind: "ConfigMap"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, ok, err := summarizeKind(tt.groupKind, ResourceResponse{}, toItem(t, service))
+			if err != nil {
+				t.Fatalf("summarizeKind() error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("summarizeKind() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && reflect.TypeOf(response) != reflect.TypeOf(tt.wantType) {
+				t.Errorf("summarizeKind() = %T, want %T", response, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// tableAcceptHeader asks for the server-side Table rendering kubectl uses,
+// falling back to plain JSON for servers that don't support it, see tableFromJSON.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// TableColumn describes a printer column, including CRD additionalPrinterColumns.
+// Priority 0 columns are the ones kubectl shows by default, higher ones only with -o wide.
+type TableColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority"`
+}
+
+// TableRow holds one cell per column, typed after the column type:
+// integer columns hold int64, number float64, boolean bool and others strings.
+// ResourceVersion tells whether the row is still current for a watched object.
+type TableRow struct {
+	Name            string        `json:"name"`
+	Namespace       string        `json:"namespace,omitempty"`
+	ResourceVersion string        `json:"resourceVersion,omitempty"`
+	Cells           []interface{} `json:"cells"`
+}
+
+// ResourceTable is a page of a resource list as rendered by the API server.
+type ResourceTable struct {
+	Columns  []TableColumn `json:"columns"`
+	Rows     []TableRow    `json:"rows"`
+	Continue string        `json:"continue,omitempty"`
+}
+
+// GetResourceTable lists resources with the columns `kubectl get` shows for them.
+// A zero limit lists everything; paging works as in ListResourcesPage.
+func (a *App) GetResourceTable(clusterName, resourceName, namespace string, opts PageOptions) (*ResourceTable, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceInfo, _, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor continueToken
+	if opts.Continue != "" {
+		if err := decodeCursor(opts.Continue, &cursor); err != nil {
+			return nil, fmt.Errorf("invalid continue token: %w", err)
+		}
+	}
+
+	listOptions := metav1.ListOptions{Limit: min(max(opts.Limit, 0), maxListPageSize), Continue: cursor.Token}
+	opts.applyTo(&listOptions)
+
+	table, err := getTable(clients, resourcePath(resourceInfo, listNamespace(namespace)), &listOptions)
+	if err != nil {
+		if errors.IsResourceExpired(err) {
+			return nil, fmt.Errorf("the list changed too much since the first page, start again from the beginning: %w", err)
+		}
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	result := toResourceTable(table)
+	if table.Continue != "" {
+		result.Continue, err = encodeCursor(continueToken{Token: table.Continue, Offset: cursor.Offset + int64(len(table.Rows))})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetResourceTableRow gets the table of a single resource, so that a list can refresh
+// the row of a changed resource without listing all of them again.
+func (a *App) GetResourceTableRow(clusterName, resourceName, namespace, name string) (*ResourceTable, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceInfo, _, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := getTable(clients, path.Join(resourcePath(resourceInfo, namespace), name), &metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource %q: %w", name, err)
+	}
+	return toResourceTable(table), nil
+}
+
+// getTable requests the Table rendering of a resource or collection, with the metadata of its objects.
+func getTable(clients *KubeClients, absPath string, options runtime.Object) (*metav1.Table, error) {
+	raw, err := clients.Clientset.Discovery().RESTClient().Get().
+		AbsPath(absPath).
+		SetHeader("Accept", tableAcceptHeader).
+		VersionedParams(options, scheme.ParameterCodec).
+		Param("includeObject", string(metav1.IncludeMetadata)).
+		Do(context.Background()).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var table metav1.Table
+	if err := json.Unmarshal(raw, &table); err != nil {
+		return nil, fmt.Errorf("failed to decode table: %w", err)
+	}
+	if table.Kind != "Table" {
+		return tableFromJSON(raw, time.Now())
+	}
+	return &table, nil
+}
+
+// tableFromJSON renders a plain JSON object or list with the name and age columns kubectl
+// prints for servers without Table support, such as some aggregated APIs.
+func tableFromJSON(raw []byte, now time.Time) (*metav1.Table, error) {
+	obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode resource: %w", err)
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+			{Name: "Age", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+		},
+	}
+	var items []unstructured.Unstructured
+	switch obj := obj.(type) {
+	case *unstructured.UnstructuredList:
+		items = obj.Items
+		table.Continue = obj.GetContinue()
+		table.ResourceVersion = obj.GetResourceVersion()
+	case *unstructured.Unstructured:
+		items = []unstructured.Unstructured{*obj}
+	}
+
+	for _, item := range items {
+		// Only the metadata, like the rows of a table requested with includeObject=Metadata
+		metadata, err := json.Marshal(map[string]interface{}{"metadata": item.Object["metadata"]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		age := "<unknown>"
+		if created := item.GetCreationTimestamp(); !created.IsZero() {
+			age = duration.HumanDuration(now.Sub(created.Time))
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  []interface{}{item.GetName(), age},
+			Object: runtime.RawExtension{Raw: metadata},
+		})
+	}
+	return table, nil
+}
+
+// resourcePath returns the collection URL path of the resource.
+func resourcePath(info ResourceInfo, namespace string) string {
+	parts := []string{"/api", info.Version}
+	if info.Group != "" {
+		parts = []string{"/apis", info.Group, info.Version}
+	}
+	if info.Namespaced && namespace != "" {
+		parts = append(parts, "namespaces", namespace)
+	}
+	return path.Join(append(parts, info.Name)...)
+}
+
+func toResourceTable(table *metav1.Table) *ResourceTable {
+	result := &ResourceTable{
+		Columns: make([]TableColumn, 0, len(table.ColumnDefinitions)),
+		Rows:    make([]TableRow, 0, len(table.Rows)),
+	}
+	for _, c := range table.ColumnDefinitions {
+		result.Columns = append(result.Columns, TableColumn{
+			Name:        c.Name,
+			Type:        c.Type,
+			Format:      c.Format,
+			Description: c.Description,
+			Priority:    c.Priority,
+		})
+	}
+
+	for _, r := range table.Rows {
+		row := TableRow{Cells: make([]interface{}, len(r.Cells))}
+		for i, cell := range r.Cells {
+			columnType := ""
+			if i < len(result.Columns) {
+				columnType = result.Columns[i].Type
+			}
+			row.Cells[i] = typedCell(columnType, cell)
+		}
+
+		var object metav1.PartialObjectMetadata
+		if len(r.Object.Raw) > 0 && json.Unmarshal(r.Object.Raw, &object) == nil {
+			row.Name = object.Name
+			row.Namespace = object.Namespace
+			row.ResourceVersion = object.ResourceVersion
+		} else if len(row.Cells) > 0 && len(result.Columns) > 0 && result.Columns[0].Name == "Name" {
+			row.Name, _ = row.Cells[0].(string)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result
+}
+
+// typedCell converts a JSON decoded cell to the Go type of its column.
+func typedCell(columnType string, cell interface{}) interface{} {
+	if cell == nil {
+		return nil
+	}
+	switch columnType {
+	case "integer":
+		if n, ok := cell.(float64); ok {
+			return int64(n)
+		}
+	case "number":
+		if n, ok := cell.(float64); ok {
+			return n
+		}
+	case "boolean":
+		if b, ok := cell.(bool); ok {
+			return b
+		}
+	}
+	if s, ok := cell.(string); ok {
+		return s
+	}
+	return fmt.Sprint(cell)
+}
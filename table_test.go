@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTableFromJSON(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		raw             string
+		wantCells       [][]interface{}
+		wantNames       []string
+		wantContinue    string
+		wantListVersion string
+	}{
+		{
+			name: "list",
+			raw: `{"kind":"WidgetList","apiVersion":"example.com/v1","metadata":{"continue":"next","resourceVersion":"42"},"items":[
+				{"kind":"Widget","apiVersion":"example.com/v1","metadata":{"name":"a","namespace":"default","creationTimestamp":"2024-05-01T11:55:00Z"}},
+				{"kind":"Widget","apiVersion":"example.com/v1","metadata":{"name":"b","namespace":"default"}}]}`,
+			wantCells:       [][]interface{}{{"a", "5m"}, {"b", "<unknown>"}},
+			wantNames:       []string{"a", "b"},
+			wantContinue:    "next",
+			wantListVersion: "42",
+		},
+		{
+			name:      "single object",
+			raw:       `{"kind":"Widget","apiVersion":"example.com/v1","metadata":{"name":"a","creationTimestamp":"2024-04-29T12:00:00Z"},"spec":{"size":3}}`,
+			wantCells: [][]interface{}{{"a", "2d"}},
+			wantNames: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := tableFromJSON([]byte(tt.raw), now)
+			if err != nil {
+				t.Fatalf("tableFromJSON() error = %v", err)
+			}
+			if len(table.ColumnDefinitions) != 2 || table.ColumnDefinitions[0].Format != "name" {
+				t.Errorf("columns = %+v, want name and age", table.ColumnDefinitions)
+			}
+			var cells [][]interface{}
+			var names []string
+			for _, row := range table.Rows {
+				cells = append(cells, row.Cells)
+				var object struct {
+					Metadata struct{ Name string } `json:"metadata"`
+				}
+				if err := json.Unmarshal(row.Object.Raw, &object); err != nil {
+					t.Fatalf("row object: %v", err)
+				}
+				names = append(names, object.Metadata.Name)
+			}
+			if !reflect.DeepEqual(cells, tt.wantCells) {
+				t.Errorf("cells = %v, want %v", cells, tt.wantCells)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("row names = %v, want %v", names, tt.wantNames)
+			}
+			if table.Continue != tt.wantContinue || table.ResourceVersion != tt.wantListVersion {
+				t.Errorf("continue, resourceVersion = %q, %q, want %q, %q", table.Continue, table.ResourceVersion, tt.wantContinue, tt.wantListVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// taskRegistry tracks running background tasks, like searches, so they can be cancelled.
+type taskRegistry struct {
+	mu     sync.Mutex
+	tasks  map[string]context.CancelFunc
+	nextID int64
+}
+
+func newTaskRegistry() *taskRegistry {
+	return &taskRegistry{tasks: make(map[string]context.CancelFunc)}
+}
+
+func (r *taskRegistry) start(cancel context.CancelFunc) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	id := strconv.FormatInt(r.nextID, 10)
+	r.tasks[id] = cancel
+	return id
+}
+
+func (r *taskRegistry) stop(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cancel, ok := r.tasks[id]; ok {
+		cancel()
+		delete(r.tasks, id)
+	}
+}
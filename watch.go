@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// watchSyncTimeout bounds how long a subscription waits for the initial list.
+	watchSyncTimeout = 30 * time.Second
+	// watchFlushInterval batches deltas so busy namespaces don't emit an event per change.
+	watchFlushInterval = 250 * time.Millisecond
+)
+
+// Delta types emitted to subscribers.
+const (
+	deltaAdded    = "added"
+	deltaModified = "modified"
+	deltaDeleted  = "deleted"
+)
+
+// ResourceDelta is a single change of a watched resource list.
+// Resource is the same response GetResourcesInNamespace returns for the item.
+type ResourceDelta struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Resource  interface{} `json:"resource,omitempty"`
+}
+
+// ResourceSubscription is returned by SubscribeResources. Deltas are emitted as
+// Wails events named Event, each carrying a []ResourceDelta.
+type ResourceSubscription struct {
+	ID    string        `json:"id"`
+	Event string        `json:"event"`
+	Items []interface{} `json:"items"`
+}
+
+// watchRegistry shares one informer per (cluster, resource, namespace) between subscribers.
+type watchRegistry struct {
+	mu            sync.Mutex
+	watches       map[string]*resourceWatch
+	subscriptions map[string]string // subscription ID -> watch key
+	nextID        int64
+}
+
+// resourceWatch is a running informer and the deltas not yet emitted.
+type resourceWatch struct {
+	key         string
+	cluster     string
+	groupKind   schema.GroupKind
+	informer    cache.SharedIndexInformer
+	cancel      context.CancelFunc
+	subscribers int
+
+	mu      sync.Mutex
+	pending []ResourceDelta
+}
+
+func newWatchRegistry() *watchRegistry {
+	return &watchRegistry{
+		watches:       make(map[string]*resourceWatch),
+		subscriptions: make(map[string]string),
+	}
+}
+
+func watchKey(clusterName, resourceName, namespace string, selectors ResourceSelectors) string {
+	key := fmt.Sprintf("%s/%s/%s", clusterName, resourceName, namespace)
+	if selectors != (ResourceSelectors{}) {
+		key += "?" + selectors.key()
+	}
+	return key
+}
+
+func (w *resourceWatch) eventName() string {
+	return "resources:" + w.key
+}
+
+func (w *resourceWatch) add(delta ResourceDelta) {
+	w.mu.Lock()
+	w.pending = append(w.pending, delta)
+	w.mu.Unlock()
+}
+
+func (w *resourceWatch) delta(deltaType string, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	item, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	delta := ResourceDelta{Type: deltaType, Name: item.GetName(), Namespace: item.GetNamespace()}
+	if deltaType != deltaDeleted {
+		delta.Resource = toResourceResponse(w.groupKind, *item)
+	}
+	w.add(delta)
+}
+
+// flush emits pending deltas until the watch is stopped.
+func (w *resourceWatch) flush(ctx context.Context, appCtx context.Context) {
+	ticker := time.NewTicker(watchFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			pending := w.pending
+			w.pending = nil
+			w.mu.Unlock()
+			if len(pending) > 0 && appCtx != nil {
+				wailsruntime.EventsEmit(appCtx, w.eventName(), pending)
+			}
+		}
+	}
+}
+
+// items returns the current state of the informer cache.
+func (w *resourceWatch) items() []interface{} {
+	items := []interface{}{}
+	for _, obj := range w.informer.GetStore().List() {
+		if item, ok := obj.(*unstructured.Unstructured); ok {
+			items = append(items, toResourceResponse(w.groupKind, *item))
+		}
+	}
+	return items
+}
+
+// SubscribeResources starts watching a resource list, or joins an existing watch of it,
+// and returns the current items. Only items matching the selectors are watched.
+// Changes are emitted as events until UnsubscribeResources.
+func (a *App) SubscribeResources(clusterName, resourceName, namespace string, selectors ResourceSelectors) (*ResourceSubscription, error) {
+	if err := selectors.validate(); err != nil {
+		return nil, err
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceInfo, gvr, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	if !resourceInfo.Namespaced {
+		namespace = ""
+	} else if namespace == AllNamespaces {
+		// Without cluster-wide access the UI polls GetResourcesInNamespace, which falls back to single namespaces
+		if err := checkClusterWideList(context.Background(), clients, gvr); err != nil {
+			return nil, fmt.Errorf("failed to watch %s in all namespaces: %w", resourceName, err)
+		}
+		namespace = ""
+	}
+
+	key := watchKey(clusterName, resourceInfo.qualifiedName(), namespace, selectors)
+
+	a.watches.mu.Lock()
+	w, exists := a.watches.watches[key]
+	if !exists {
+		ctx, cancel := context.WithCancel(context.Background())
+		informer := dynamicinformer.NewFilteredDynamicInformer(clients.StreamDynamicClient, gvr, namespace, 0, cache.Indexers{}, func(opts *metav1.ListOptions) {
+			selectors.applyTo(opts)
+		})
+		w = &resourceWatch{
+			key:       key,
+			cluster:   clusterName,
+			groupKind: schema.GroupKind{Group: gvr.Group, Kind: resourceInfo.Kind},
+			informer:  informer.Informer(),
+			cancel:    cancel,
+		}
+		_, err := w.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj interface{}, isInInitialList bool) {
+				// Subscribers get the initial list from SubscribeResources
+				if !isInInitialList {
+					w.delta(deltaAdded, obj)
+				}
+			},
+			UpdateFunc: func(_, obj interface{}) { w.delta(deltaModified, obj) },
+			DeleteFunc: func(obj interface{}) { w.delta(deltaDeleted, obj) },
+		})
+		if err != nil {
+			cancel()
+			a.watches.mu.Unlock()
+			return nil, fmt.Errorf("failed to watch %s: %w", resourceName, err)
+		}
+		w.informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+			log.Printf("Watch of %s failed: %v", key, err)
+		})
+		go w.informer.Run(ctx.Done())
+		go w.flush(ctx, a.ctx)
+		a.watches.watches[key] = w
+		log.Printf("Started watch of %s", key)
+	}
+	w.subscribers++
+	a.watches.nextID++
+	id := strconv.FormatInt(a.watches.nextID, 10)
+	a.watches.subscriptions[id] = key
+	a.watches.mu.Unlock()
+
+	syncCtx, cancel := context.WithTimeout(context.Background(), watchSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), w.informer.HasSynced) {
+		a.UnsubscribeResources(id)
+		return nil, fmt.Errorf("timed out listing %s", resourceName)
+	}
+
+	return &ResourceSubscription{ID: id, Event: w.eventName(), Items: w.items()}, nil
+}
+
+// UnsubscribeResources ends a subscription. The watch stops with its last subscriber.
+func (a *App) UnsubscribeResources(subscriptionID string) {
+	a.watches.mu.Lock()
+	defer a.watches.mu.Unlock()
+
+	key, ok := a.watches.subscriptions[subscriptionID]
+	if !ok {
+		return
+	}
+	delete(a.watches.subscriptions, subscriptionID)
+
+	if w, ok := a.watches.watches[key]; ok {
+		w.subscribers--
+		if w.subscribers <= 0 {
+			w.cancel()
+			delete(a.watches.watches, key)
+			log.Printf("Stopped watch of %s", key)
+		}
+	}
+}
+
+// stopCluster stops all watches of the cluster, e.g. when it is disconnected.
+func (r *watchRegistry) stopCluster(clusterName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, w := range r.watches {
+		if w.cluster != clusterName {
+			continue
+		}
+		w.cancel()
+		delete(r.watches, key)
+		for id, subscriptionKey := range r.subscriptions {
+			if subscriptionKey == key {
+				delete(r.subscriptions, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// maxWorkloadLogStreams limits concurrent log requests per session, like stern's --max-log-requests.
+	maxWorkloadLogStreams = 50
+	// workloadLogBuffer is how many lines may be in flight before pod streams block.
+	workloadLogBuffer = 1024
+	// workloadLogReorderWindow is how long lines are held back so that lines from
+	// different pods can be emitted in timestamp order.
+	workloadLogReorderWindow = time.Second
+	workloadLogFlushInterval = 250 * time.Millisecond
+)
+
+// WorkloadLogLine is a log line of one container of a workload's pod.
+// Event is set instead of Message when a container stream starts or ends, or is
+// queued because maxWorkloadLogStreams containers are streamed already.
+type WorkloadLogLine struct {
+	Pod       string    `json:"pod"`
+	Container string    `json:"container"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message,omitempty"`
+	Event     string    `json:"event,omitempty"`
+
+	received time.Time
+	seq      int64
+}
+
+// workloadPodSelector returns the label selector of the pods backing a workload or service.
+func workloadPodSelector(obj *unstructured.Unstructured) (labels.Selector, error) {
+	spec := extractMap(obj.Object, "spec")
+
+	if obj.GetKind() == "Service" {
+		selector := selectorFromMap(extractMap(spec, "selector"))
+		if selector == "" {
+			return nil, fmt.Errorf("service %s has no pod selector", obj.GetName())
+		}
+		return labels.Parse(selector)
+	}
+
+	selectorMap := extractMap(spec, "selector")
+	if len(selectorMap) == 0 {
+		return nil, fmt.Errorf("%s %s has no pod selector", obj.GetKind(), obj.GetName())
+	}
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, &labelSelector); err != nil {
+		return nil, fmt.Errorf("invalid selector of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s %s has an empty pod selector", obj.GetKind(), obj.GetName())
+	}
+	return selector, nil
+}
+
+// checkWorkloadLogOptions rejects the pod log options a merged stream can't honour. It always
+// follows the pods and orders their lines by timestamp, and the previous instances of the
+// containers have no place in that order.
+func checkWorkloadLogOptions(query url.Values, opts *corev1.PodLogOptions) error {
+	switch {
+	case opts.Previous:
+		return fmt.Errorf("previous is not supported for workload logs, open the logs of a single pod")
+	case query.Get("follow") != "" && !opts.Follow:
+		return fmt.Errorf("workload logs are always followed")
+	case query.Get("timestamps") != "" && !opts.Timestamps:
+		return fmt.Errorf("workload logs always carry timestamps, their lines are ordered by them")
+	}
+	return nil
+}
+
+// workloadLogStream is the log stream of one container.
+type workloadLogStream struct {
+	pod  string
+	opts *corev1.PodLogOptions
+}
+
+// workloadLogSession multiplexes the log streams of all pods matching a selector.
+type workloadLogSession struct {
+	clients    *KubeClients
+	namespace  string
+	container  string
+	tailLines  *int64
+	since      *int64
+	sinceTime  *metav1.Time
+	limitBytes *int64
+
+	lines chan WorkloadLogLine
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[string]bool
+	queued  []workloadLogStream // Waiting for a free stream, in the order they appeared
+	active  int
+	closed  bool
+	seq     int64
+}
+
+// close waits for the pod streams to finish once the context is cancelled.
+func (s *workloadLogSession) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *workloadLogSession) emit(ctx context.Context, line WorkloadLogLine) bool {
+	line.received = time.Now()
+	select {
+	case s.lines <- line:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// podChanged starts streams for containers of the pod that are running or have
+// terminated and aren't streamed yet. Containers are keyed by container ID, so a
+// restarted container gets a new stream. Beyond maxWorkloadLogStreams they are
+// queued until another stream ends.
+func (s *workloadLogSession) podChanged(ctx context.Context, pod *corev1.Pod, initial bool) {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if s.container != "" && status.Name != s.container {
+			continue
+		}
+		if status.ContainerID == "" || (status.State.Running == nil && status.State.Terminated == nil) {
+			continue
+		}
+
+		opts := &corev1.PodLogOptions{
+			Container:  status.Name,
+			Follow:     true,
+			Timestamps: true,
+			LimitBytes: s.limitBytes,
+		}
+		// Containers appearing later are streamed from their start
+		if initial {
+			opts.TailLines = s.tailLines
+			opts.SinceSeconds = s.since
+			opts.SinceTime = s.sinceTime
+		}
+		stream := workloadLogStream{pod: pod.Name, opts: opts}
+
+		s.mu.Lock()
+		if s.closed || s.streams[status.ContainerID] {
+			s.mu.Unlock()
+			continue
+		}
+		s.streams[status.ContainerID] = true
+		if s.active < maxWorkloadLogStreams {
+			s.startLocked(ctx, stream)
+			s.mu.Unlock()
+			continue
+		}
+		s.queued = append(s.queued, stream)
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		log.Printf("Queueing logs of %s/%s: limit of %d streams reached", pod.Name, status.Name, maxWorkloadLogStreams)
+		// Not from the informer's goroutine, emit blocks while the client is slow
+		go func() {
+			defer s.wg.Done()
+			s.emit(ctx, WorkloadLogLine{Pod: stream.pod, Container: opts.Container, Timestamp: time.Now(), Event: "queued"})
+		}()
+	}
+}
+
+// startLocked streams the container in the background. When the stream ends, the next
+// queued one takes its place. s.mu must be held.
+func (s *workloadLogSession) startLocked(ctx context.Context, stream workloadLogStream) {
+	s.active++
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.streamContainer(ctx, stream.pod, stream.opts)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.active--
+		if len(s.queued) > 0 && !s.closed {
+			next := s.queued[0]
+			s.queued = s.queued[1:]
+			s.startLocked(ctx, next)
+		}
+	}()
+}
+
+func (s *workloadLogSession) streamContainer(ctx context.Context, podName string, opts *corev1.PodLogOptions) {
+	if !s.emit(ctx, WorkloadLogLine{Pod: podName, Container: opts.Container, Timestamp: time.Now(), Event: "started"}) {
+		return
+	}
+	// A closure, so that the timestamp is taken when the stream ends
+	defer func() {
+		s.emit(ctx, WorkloadLogLine{Pod: podName, Container: opts.Container, Timestamp: time.Now(), Event: "ended"})
+	}()
+
+	stream, err := s.clients.StreamClientset.CoreV1().Pods(s.namespace).GetLogs(podName, opts).Stream(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to stream logs of %s/%s: %v", podName, opts.Container, err)
+		}
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		timestamp, message := splitLogTimestamp(scanner.Text())
+		if !s.emit(ctx, WorkloadLogLine{Pod: podName, Container: opts.Container, Timestamp: timestamp, Message: message}) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		log.Printf("Log stream of %s/%s failed: %v", podName, opts.Container, err)
+	}
+}
+
+// splitLogTimestamp separates the RFC3339 timestamp added by timestamps=true from the message.
+func splitLogTimestamp(line string) (time.Time, string) {
+	if prefix, message, found := strings.Cut(line, " "); found {
+		if timestamp, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+			return timestamp, message
+		}
+	}
+	return time.Now(), line
+}
+
+// logLineHeap orders pending lines by timestamp, then by arrival.
+type logLineHeap []WorkloadLogLine
+
+func (h logLineHeap) Len() int { return len(h) }
+func (h logLineHeap) Less(i, j int) bool {
+	if !h[i].Timestamp.Equal(h[j].Timestamp) {
+		return h[i].Timestamp.Before(h[j].Timestamp)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h logLineHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *logLineHeap) Push(x any)   { *h = append(*h, x.(WorkloadLogLine)) }
+func (h *logLineHeap) Pop() any {
+	old := *h
+	line := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return line
+}
+
+// merge reorders lines within the reorder window and sends them in batches.
+// send blocks while the client is slow, which in turn blocks the pod streams.
+func (s *workloadLogSession) merge(ctx context.Context, send func([]WorkloadLogLine) error) error {
+	pending := &logLineHeap{}
+	ticker := time.NewTicker(workloadLogFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line := <-s.lines:
+			s.seq++
+			line.seq = s.seq
+			heap.Push(pending, line)
+		case <-ticker.C:
+			cutoff := time.Now().Add(-workloadLogReorderWindow)
+			var batch []WorkloadLogLine
+			for pending.Len() > 0 && (*pending)[0].received.Before(cutoff) {
+				batch = append(batch, heap.Pop(pending).(WorkloadLogLine))
+			}
+			if len(batch) > 0 {
+				if err := send(batch); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// handleWorkloadLogsWebSocket streams the merged logs of every pod of a workload or service.
+// Query parameters: cluster, namespace, resource, name and optionally container, tailLines,
+// sinceSeconds or sinceTime, and limitBytes, which applies to each container's stream.
+// Options that checkWorkloadLogOptions rejects close the connection with the reason.
+func (a *App) handleWorkloadLogsWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	query := r.URL.Query()
+	clusterName := query.Get("cluster")
+	namespace := query.Get("namespace")
+	resourceName := query.Get("resource")
+	name := query.Get("name")
+
+	if clusterName == "" || namespace == "" || resourceName == "" || name == "" {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Missing required parameters"))
+		return
+	}
+
+	opts, err := podLogOptionsFromQuery(query)
+	if err == nil {
+		err = checkWorkloadLogOptions(query, opts)
+	}
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseUnsupportedData, err.Error()))
+		return
+	}
+
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+
+	selector, err := a.resolveWorkloadSelector(ctx, clusterName, clients, namespace, resourceName, name)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+
+	session := &workloadLogSession{
+		clients:    clients,
+		namespace:  namespace,
+		container:  opts.Container,
+		tailLines:  opts.TailLines,
+		since:      opts.SinceSeconds,
+		sinceTime:  opts.SinceTime,
+		limitBytes: opts.LimitBytes,
+		lines:      make(chan WorkloadLogLine, workloadLogBuffer),
+		streams:    make(map[string]bool),
+	}
+
+	// Reading is required to notice the client going away
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	informer := cache.NewSharedIndexInformer(
+		cache.NewFilteredListWatchFromClient(clients.StreamClientset.CoreV1().RESTClient(), "pods", namespace, func(options *metav1.ListOptions) {
+			options.LabelSelector = selector.String()
+		}),
+		&corev1.Pod{},
+		0,
+		cache.Indexers{},
+	)
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				session.podChanged(ctx, pod, isInInitialList)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				session.podChanged(ctx, pod, false)
+			}
+		},
+	})
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+
+	log.Printf("Streaming logs of %s %s/%s (selector %s)", resourceName, namespace, name, selector)
+	go informer.Run(ctx.Done())
+
+	err = session.merge(ctx, func(batch []WorkloadLogLine) error {
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		return conn.WriteMessage(websocket.TextMessage, data)
+	})
+	cancel()
+	session.close()
+
+	if err != nil {
+		log.Printf("Workload log stream error: %v", err)
+	}
+}
+
+// resolveWorkloadSelector fetches the workload or service and returns its pod selector.
+func (a *App) resolveWorkloadSelector(ctx context.Context, clusterName string, clients *KubeClients, namespace, resourceName, name string) (labels.Selector, error) {
+	_, gvr, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := clients.DynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", resourceName, name, err)
+	}
+	return workloadPodSelector(obj)
+}
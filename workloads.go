@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// WorkloadScale is the scale subresource of a workload or custom resource.
+type WorkloadScale struct {
+	Replicas        int32  `json:"replicas"`        // Desired replicas
+	CurrentReplicas int32  `json:"currentReplicas"` // Replicas observed by the controller
+	Selector        string `json:"selector,omitempty"`
+}
+
+// RolloutRevision is an entry of a workload's rollout history. Deployments keep their
+// revisions in ReplicaSets, StatefulSets and DaemonSets in ControllerRevisions; Name is
+// the name of that object.
+type RolloutRevision struct {
+	Revision    int64    `json:"revision"`
+	Name        string   `json:"name"`
+	ChangeCause string   `json:"changeCause,omitempty"`
+	Images      []string `json:"images"`
+	Age         string   `json:"age"`
+	Current     bool     `json:"current"`
+}
+
+// rolloutKind returns the kind of an apps/v1 workload with rollouts, or an error for other resources.
+func rolloutKind(info ResourceInfo) (string, error) {
+	if info.Group == appsv1.GroupName {
+		switch info.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet":
+			return info.Kind, nil
+		}
+	}
+	return "", fmt.Errorf("%s have no rollouts, only deployments, statefulsets and daemonsets do", info.qualifiedName())
+}
+
+// hasSubresource reports whether the API server serves the subresource of the resource, e.g. "scale".
+func hasSubresource(clients *KubeClients, info ResourceInfo, subresource string) (bool, error) {
+	_, resourceLists, err := clients.discovery.groupsAndResources()
+	if err != nil && len(resourceLists) == 0 {
+		return false, fmt.Errorf("failed to get API resources: %w", err)
+	}
+	groupVersion := schema.GroupVersion{Group: info.Group, Version: info.Version}.String()
+	for _, list := range resourceLists {
+		if list.GroupVersion != groupVersion {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if r.Name == info.Name+"/"+subresource {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (a *App) scalableResource(clusterName, resourceName string) (*KubeClients, ResourceInfo, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, ResourceInfo{}, err
+	}
+	resourceInfo, _, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, ResourceInfo{}, err
+	}
+	scalable, err := hasSubresource(clients, resourceInfo, "scale")
+	if err != nil {
+		return nil, ResourceInfo{}, err
+	}
+	if !scalable {
+		return nil, ResourceInfo{}, fmt.Errorf("%s can't be scaled", resourceInfo.qualifiedName())
+	}
+	return clients, resourceInfo, nil
+}
+
+// GetScale returns the scale of any resource with a /scale subresource,
+// including custom resources that enable it.
+func (a *App) GetScale(clusterName, namespace, resourceName, name string) (*WorkloadScale, error) {
+	clients, resourceInfo, err := a.scalableResource(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceClient := resourceInterface(clients.DynamicClient, resourceInfo.GroupVersionResource(), resourceInfo.Namespaced, namespace)
+	obj, err := resourceClient.Get(context.Background(), name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale of %s %q: %w", resourceName, name, err)
+	}
+	var scale autoscalingv1.Scale
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &scale); err != nil {
+		return nil, fmt.Errorf("failed to decode scale of %s %q: %w", resourceName, name, err)
+	}
+	return &WorkloadScale{
+		Replicas:        scale.Spec.Replicas,
+		CurrentReplicas: scale.Status.Replicas,
+		Selector:        scale.Status.Selector,
+	}, nil
+}
+
+// ScaleResource sets the replicas through the /scale subresource, like kubectl scale.
+func (a *App) ScaleResource(clusterName, namespace, resourceName, name string, replicas int32) error {
+	if replicas < 0 {
+		return fmt.Errorf("replicas must not be negative")
+	}
+	clients, resourceInfo, err := a.scalableResource(clusterName, resourceName)
+	if err != nil {
+		return err
+	}
+
+	resourceClient := resourceInterface(clients.DynamicClient, resourceInfo.GroupVersionResource(), resourceInfo.Namespaced, namespace)
+	patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)
+	if _, err := resourceClient.Patch(context.Background(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{}, "scale"); err != nil {
+		return fmt.Errorf("failed to scale %s %q: %w", resourceName, name, err)
+	}
+	log.Printf("Scaled %s %q in namespace %q of cluster %q to %d replicas", resourceName, name, namespace, clusterName, replicas)
+	return nil
+}
+
+// RestartWorkload restarts the pods of a Deployment, StatefulSet or DaemonSet like
+// kubectl rollout restart, by changing an annotation of the pod template.
+func (a *App) RestartWorkload(clusterName, namespace, resourceName, name string) error {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return err
+	}
+	resourceInfo, gvr, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return err
+	}
+	kind, err := rolloutKind(resourceInfo)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if kind == "Deployment" {
+		d, err := clients.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get deployment %q: %w", name, err)
+		}
+		if d.Spec.Paused {
+			return fmt.Errorf("can't restart paused deployment %q, resume it first", name)
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{restartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := clients.DynamicClient.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to restart %s %q: %w", resourceName, name, err)
+	}
+	log.Printf("Restarted %s %q in namespace %q of cluster %q", resourceName, name, namespace, clusterName)
+	return nil
+}
+
+// PauseRollout pauses a Deployment, so that template changes are not rolled out until it is resumed.
+func (a *App) PauseRollout(clusterName, namespace, name string) error {
+	return a.setRolloutPaused(clusterName, namespace, name, true)
+}
+
+// ResumeRollout resumes a paused Deployment.
+func (a *App) ResumeRollout(clusterName, namespace, name string) error {
+	return a.setRolloutPaused(clusterName, namespace, name, false)
+}
+
+func (a *App) setRolloutPaused(clusterName, namespace, name string, paused bool) error {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	deployments := clients.Clientset.AppsV1().Deployments(namespace)
+	d, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get deployment %q: %w", name, err)
+	}
+	if d.Spec.Paused == paused {
+		if paused {
+			return fmt.Errorf("deployment %q is already paused", name)
+		}
+		return fmt.Errorf("deployment %q is not paused", name)
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"paused":%t}}`, paused)
+	if _, err := deployments.Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to update deployment %q: %w", name, err)
+	}
+	log.Printf("Set paused=%t on deployment %q in namespace %q of cluster %q", paused, name, namespace, clusterName)
+	return nil
+}
+
+// GetRolloutHistory lists the revisions of a Deployment, StatefulSet or DaemonSet, oldest first.
+func (a *App) GetRolloutHistory(clusterName, namespace, resourceName, name string) ([]RolloutRevision, error) {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	resourceInfo, _, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := rolloutKind(resourceInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []RolloutRevision
+	if kind == "Deployment" {
+		_, replicaSets, err := deploymentReplicaSets(clients, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, rs := range replicaSets {
+			revisions = append(revisions, replicaSetRevision(rs))
+		}
+	} else {
+		_, controllerRevisions, err := workloadControllerRevisions(clients, kind, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, cr := range controllerRevisions {
+			revisions = append(revisions, controllerRevision(cr))
+		}
+	}
+	if len(revisions) > 0 {
+		revisions[len(revisions)-1].Current = true
+	}
+	return revisions, nil
+}
+
+// RollbackWorkload rolls a Deployment, StatefulSet or DaemonSet back to a revision of
+// its history, like kubectl rollout undo --to-revision.
+func (a *App) RollbackWorkload(clusterName, namespace, resourceName, name string, revision int64) error {
+	clients, err := a.getKubeClients(clusterName)
+	if err != nil {
+		return err
+	}
+	resourceInfo, _, err := a.findResourceInfo(clusterName, resourceName)
+	if err != nil {
+		return err
+	}
+	kind, err := rolloutKind(resourceInfo)
+	if err != nil {
+		return err
+	}
+
+	if kind == "Deployment" {
+		err = rollbackDeployment(clients, namespace, name, revision)
+	} else {
+		err = rollbackControllerRevision(clients, kind, namespace, name, revision)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Rolled back %s %q in namespace %q of cluster %q to revision %d", resourceName, name, namespace, clusterName, revision)
+	return nil
+}
+
+func rollbackDeployment(clients *KubeClients, namespace, name string, revision int64) error {
+	d, replicaSets, err := deploymentReplicaSets(clients, namespace, name)
+	if err != nil {
+		return err
+	}
+	if d.Spec.Paused {
+		return fmt.Errorf("can't roll back paused deployment %q, resume it first", name)
+	}
+
+	var target *appsv1.ReplicaSet
+	for _, rs := range replicaSets {
+		if annotationRevision(rs) == revision {
+			target = rs
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("revision %d of deployment %q not found", revision, name)
+	}
+	if len(replicaSets) > 0 && replicaSets[len(replicaSets)-1] == target {
+		return fmt.Errorf("deployment %q is already at revision %d", name, revision)
+	}
+
+	// The ReplicaSet's template without the hash label the deployment controller adds
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	operations := []map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": d.ResourceVersion},
+		{"op": "replace", "path": "/spec/template", "value": template},
+	}
+	if cause, ok := target.Annotations[changeCauseAnnotation]; ok {
+		if d.Annotations == nil {
+			operations = append(operations, map[string]interface{}{"op": "add", "path": "/metadata/annotations", "value": map[string]string{}})
+		}
+		operations = append(operations, map[string]interface{}{"op": "add", "path": "/metadata/annotations/kubernetes.io~1change-cause", "value": cause})
+	}
+	patch, err := json.Marshal(operations)
+	if err != nil {
+		return err
+	}
+	if _, err := clients.Clientset.AppsV1().Deployments(namespace).Patch(context.Background(), name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to roll back deployment %q: %w", name, err)
+	}
+	return nil
+}
+
+// rollbackControllerRevision applies the template stored in the revision, which is a
+// strategic merge patch of the workload.
+func rollbackControllerRevision(clients *KubeClients, kind, namespace, name string, revision int64) error {
+	_, controllerRevisions, err := workloadControllerRevisions(clients, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	var target *appsv1.ControllerRevision
+	for _, cr := range controllerRevisions {
+		if cr.Revision == revision {
+			target = cr
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("revision %d of %s %q not found", revision, kind, name)
+	}
+	if controllerRevisions[len(controllerRevisions)-1] == target {
+		return fmt.Errorf("%s %q is already at revision %d", kind, name, revision)
+	}
+
+	ctx := context.Background()
+	apps := clients.Clientset.AppsV1()
+	if kind == "StatefulSet" {
+		_, err = apps.StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, target.Data.Raw, metav1.PatchOptions{})
+	} else {
+		_, err = apps.DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, target.Data.Raw, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll back %s %q: %w", kind, name, err)
+	}
+	return nil
+}
+
+// deploymentReplicaSets returns the deployment and the ReplicaSets it owns, oldest revision first.
+func deploymentReplicaSets(clients *KubeClients, namespace, name string) (*appsv1.Deployment, []*appsv1.ReplicaSet, error) {
+	ctx := context.Background()
+	d, err := clients.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deployment %q: %w", name, err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid selector of deployment %q: %w", name, err)
+	}
+
+	list, err := clients.Clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list replica sets of deployment %q: %w", name, err)
+	}
+	var replicaSets []*appsv1.ReplicaSet
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], d) {
+			replicaSets = append(replicaSets, &list.Items[i])
+		}
+	}
+	sort.SliceStable(replicaSets, func(i, j int) bool {
+		return annotationRevision(replicaSets[i]) < annotationRevision(replicaSets[j])
+	})
+	return d, replicaSets, nil
+}
+
+// workloadControllerRevisions returns the ControllerRevisions of a StatefulSet or DaemonSet, oldest first.
+func workloadControllerRevisions(clients *KubeClients, kind, namespace, name string) (metav1.Object, []*appsv1.ControllerRevision, error) {
+	ctx := context.Background()
+	apps := clients.Clientset.AppsV1()
+
+	var owner metav1.Object
+	var labelSelector *metav1.LabelSelector
+	if kind == "StatefulSet" {
+		sts, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get statefulset %q: %w", name, err)
+		}
+		owner, labelSelector = sts, sts.Spec.Selector
+	} else {
+		ds, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get daemonset %q: %w", name, err)
+		}
+		owner, labelSelector = ds, ds.Spec.Selector
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid selector of %s %q: %w", kind, name, err)
+	}
+
+	list, err := apps.ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list revisions of %s %q: %w", kind, name, err)
+	}
+	var revisions []*appsv1.ControllerRevision
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], owner) {
+			revisions = append(revisions, &list.Items[i])
+		}
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return owner, revisions, nil
+}
+
+func annotationRevision(obj metav1.Object) int64 {
+	revision, _ := strconv.ParseInt(obj.GetAnnotations()[revisionAnnotation], 10, 64)
+	return revision
+}
+
+func replicaSetRevision(rs *appsv1.ReplicaSet) RolloutRevision {
+	return RolloutRevision{
+		Revision:    annotationRevision(rs),
+		Name:        rs.Name,
+		ChangeCause: rs.Annotations[changeCauseAnnotation],
+		Images:      podSpecImages(rs.Spec.Template.Spec),
+		Age:         formatAge(rs.CreationTimestamp.Format(timeFormat)),
+	}
+}
+
+func controllerRevision(cr *appsv1.ControllerRevision) RolloutRevision {
+	var data map[string]interface{}
+	_ = json.Unmarshal(cr.Data.Raw, &data)
+	return RolloutRevision{
+		Revision:    cr.Revision,
+		Name:        cr.Name,
+		ChangeCause: cr.Annotations[changeCauseAnnotation],
+		Images:      podTemplateImages(data),
+		Age:         formatAge(cr.CreationTimestamp.Format(timeFormat)),
+	}
+}
+
+func podSpecImages(spec corev1.PodSpec) []string {
+	var images []string
+	for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		images = append(images, c.Image)
+	}
+	return images
+}